@@ -0,0 +1,157 @@
+// Package client is the Go client of the prio http api, the errors of the worker are mapped back to the api.Error* sentinels
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+type (
+	// Client : Calls the /v1 api of a prio worker
+	Client struct {
+		baseURL    string
+		httpClient *http.Client
+	}
+
+	Option = func(c *Client)
+
+	// errorResponse : Error body returned by the worker
+	errorResponse struct {
+		Code    string
+		Message string
+	}
+)
+
+// WithHTTPClient : Uses the http client for the calls, defaults to http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New : Returns a client of the worker at baseURL, e.g. http://localhost:4000
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	var res api.RegisterTopicResponse
+	err := c.do(ctx, http.MethodPost, "/v1/topics", nil, req, &res)
+	return res, err
+}
+
+func (c *Client) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	var res api.EnqueueResponse
+	err := c.do(ctx, http.MethodPost, "/v1/enqueue", nil, req, &res)
+	return res, err
+}
+
+// Dequeue : Claims the top priority job of the topic, the response is empty if the topic has none.
+// With a WaitTimeout the call is held by the worker until a job arrives or the timeout elapses
+func (c *Client) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	var query url.Values
+	if req.WaitTimeout > 0 {
+		query = url.Values{"wait": {req.WaitTimeout.String()}}
+	}
+	var res api.DequeueResponse
+	err := c.do(ctx, http.MethodGet, "/v1/dequeue", query, req, &res)
+	return res, err
+}
+
+func (c *Client) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	var res api.GetJobResponse
+	err := c.do(ctx, http.MethodGet, "/v1/jobs/"+strconv.FormatInt(req.JobId, 10), nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	var res api.AckResponse
+	err := c.do(ctx, http.MethodPost, "/v1/ack", nil, req, &res)
+	return res, err
+}
+
+// ExtendLease : Restarts the lease of a claimed job, so that it is not re-queued while it is still processed
+func (c *Client) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	var res api.LeaseResponse
+	err := c.do(ctx, http.MethodPost, "/v1/extend", nil, req, &res)
+	return res, err
+}
+
+// Nack : Moves a claimed job back to pending, so that it is retried without waiting for its lease to expire
+func (c *Client) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	var res api.LeaseResponse
+	err := c.do(ctx, http.MethodPost, "/v1/nack", nil, req, &res)
+	return res, err
+}
+
+// do : Sends the request with body as json and decodes the response into out, a 204 leaves out untouched
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeError : Maps the error code of the body to its api error, a body without a code is an api.ErrorGeneral
+func decodeError(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var body errorResponse
+	if json.Unmarshal(data, &body) == nil && body.Code != "" {
+		apiErr := api.ErrorForCode(body.Code)
+		if body.Message == "" || body.Message == apiErr.Error() {
+			return apiErr
+		}
+		return fmt.Errorf("%w: %s", apiErr, body.Message)
+	}
+	return fmt.Errorf("%w: %s %s", api.ErrorGeneral, resp.Status, bytes.TrimSpace(data))
+}
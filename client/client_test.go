@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+// fakeWorker : Serves the /v1 routes used by the client on top of an in memory queue
+type fakeWorker struct {
+	mu        sync.Mutex
+	nextId    int64
+	pending   []api.DequeueResponse
+	claimed   map[int64]string
+	acked     map[int64]bool
+	nacked    map[int64]bool
+	extended  map[int64]int
+	extendErr string // extendErr: error code returned by the extend calls
+	waits     []string
+}
+
+func newFakeWorker(t *testing.T) (*fakeWorker, *Client) {
+	f := &fakeWorker{
+		claimed:  make(map[int64]string),
+		acked:    make(map[int64]bool),
+		nacked:   make(map[int64]bool),
+		extended: make(map[int64]int),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, New(srv.URL)
+}
+
+func (f *fakeWorker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var lease api.LeaseRequest
+	switch r.URL.Path {
+	case "/v1/enqueue":
+		var req api.EnqueueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.nextId++
+		f.pending = append(f.pending, api.DequeueResponse{JobId: f.nextId, Topic: req.Topic, Payload: req.Payload, Priority: req.Priority})
+		writeJSON(w, http.StatusOK, api.EnqueueResponse{JobId: f.nextId})
+	case "/v1/dequeue":
+		var req api.DequeueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.waits = append(f.waits, r.URL.Query().Get("wait"))
+		if len(f.pending) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		job := f.pending[0]
+		f.pending = f.pending[1:]
+		f.claimed[job.JobId] = req.Consumer
+		writeJSON(w, http.StatusOK, job)
+	case "/v1/ack":
+		_ = json.NewDecoder(r.Body).Decode(&lease)
+		if f.settle(w, lease) {
+			f.acked[lease.JobId] = true
+			writeJSON(w, http.StatusOK, api.AckResponse{Acked: true})
+		}
+	case "/v1/nack":
+		_ = json.NewDecoder(r.Body).Decode(&lease)
+		if f.settle(w, lease) {
+			f.nacked[lease.JobId] = true
+			writeJSON(w, http.StatusOK, api.LeaseResponse{Ok: true})
+		}
+	case "/v1/extend":
+		_ = json.NewDecoder(r.Body).Decode(&lease)
+		if f.extendErr != "" {
+			writeJSON(w, http.StatusGone, errorResponse{Code: f.extendErr})
+			return
+		}
+		f.extended[lease.JobId]++
+		writeJSON(w, http.StatusOK, api.LeaseResponse{Ok: true})
+	default:
+		writeJSON(w, http.StatusNotFound, errorResponse{Code: "JOB_NOT_PRESENT", Message: "job not present"})
+	}
+}
+
+// settle : Releases the claim of the job, it writes the error if the job is not claimed by the consumer
+func (f *fakeWorker) settle(w http.ResponseWriter, req api.LeaseRequest) bool {
+	consumer, ok := f.claimed[req.JobId]
+	switch {
+	case !ok:
+		writeJSON(w, http.StatusNotFound, errorResponse{Code: "JOB_NOT_PRESENT"})
+	case consumer != req.Consumer:
+		writeJSON(w, http.StatusConflict, errorResponse{Code: "WRONG_CONSUMER", Message: "claimed by " + consumer})
+	default:
+		delete(f.claimed, req.JobId)
+		return true
+	}
+	return false
+}
+
+// settled : Returns the acked and nacked jobs
+func (f *fakeWorker) settled() (acked, nacked map[int64]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	acked, nacked = make(map[int64]bool), make(map[int64]bool)
+	for id := range f.acked {
+		acked[id] = true
+	}
+	for id := range f.nacked {
+		nacked[id] = true
+	}
+	return acked, nacked
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestClient(t *testing.T) {
+	f, c := newFakeWorker(t)
+	ctx := context.Background()
+
+	res, err := c.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1", WaitTimeout: 5 * time.Second})
+	if err != nil || res.JobId != 0 {
+		t.Fatalf("Dequeue() of an empty topic got = %v, err=%v", res, err)
+	}
+	if f.waits[0] != "5s" {
+		t.Errorf("Dequeue() wait got = %q, want 5s", f.waits[0])
+	}
+
+	enq, err := c.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Payload: []byte("p1"), Priority: 1})
+	if err != nil || enq.JobId != 1 {
+		t.Fatalf("Enqueue() got = %v, err=%v", enq, err)
+	}
+
+	res, err = c.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	if err != nil || res.JobId != 1 || string(res.Payload) != "p1" {
+		t.Fatalf("Dequeue() got = %v, err=%v", res, err)
+	}
+
+	_, err = c.Ack(ctx, api.AckRequest{JobId: 1, Consumer: "c2"})
+	if !errors.Is(err, api.ErrorWrongConsumer) || !strings.Contains(err.Error(), "claimed by c1") {
+		t.Errorf("Ack() by another consumer err = %v, want %v", err, api.ErrorWrongConsumer)
+	}
+
+	ack, err := c.Ack(ctx, api.AckRequest{JobId: 1, Consumer: "c1"})
+	if err != nil || !ack.Acked {
+		t.Fatalf("Ack() got = %v, err=%v", ack, err)
+	}
+
+	if _, err = c.Nack(ctx, api.LeaseRequest{JobId: 1, Consumer: "c1"}); err != api.ErrorJobNotPresent {
+		t.Errorf("Nack() of a settled job err = %v, want %v", err, api.ErrorJobNotPresent)
+	}
+
+	if _, err = c.GetJob(ctx, api.GetJobRequest{JobId: 1}); err != api.ErrorJobNotPresent {
+		t.Errorf("GetJob() err = %v, want %v", err, api.ErrorJobNotPresent)
+	}
+}
+
+func TestClient_ErrorWithoutCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, struct{}{})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).Enqueue(context.Background(), api.EnqueueRequest{Topic: "t1"})
+	if !errors.Is(err, api.ErrorGeneral) || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Enqueue() err = %v, want %v with the status", err, api.ErrorGeneral)
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	f, c := newFakeWorker(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, p := range []string{"ok", "fail", "slow", "panic"} {
+		if _, err := c.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Payload: []byte(p)}); err != nil {
+			t.Fatalf("Enqueue() err = %v", err)
+		}
+	}
+
+	handler := func(ctx context.Context, job api.DequeueResponse) error {
+		switch string(job.Payload) {
+		case "fail":
+			return errors.New("failed")
+		case "slow":
+			time.Sleep(50 * time.Millisecond)
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	}
+	cs := c.NewConsumer("t1", "c1", handler, WithConcurrency(2), WithWaitTimeout(10*time.Millisecond), WithLeaseExtension(10*time.Millisecond))
+
+	done := make(chan error)
+	go func() { done <- cs.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		acked, nacked := f.settled()
+		if len(acked)+len(nacked) == 4 {
+			if !acked[1] || !nacked[2] || !acked[3] || !nacked[4] {
+				t.Errorf("settled jobs got acked = %v, nacked = %v", acked, nacked)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("jobs not settled, acked = %v, nacked = %v", acked, nacked)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	f.mu.Lock()
+	extended := f.extended[3]
+	f.mu.Unlock()
+	if extended == 0 {
+		t.Errorf("lease of the slow job was not extended")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() err = %v", err)
+	}
+}
+
+func TestConsumer_LeaseLost(t *testing.T) {
+	f, c := newFakeWorker(t)
+	f.extendErr = "LEASE_EXCEEDED"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := c.Enqueue(ctx, api.EnqueueRequest{Topic: "t1"}); err != nil {
+		t.Fatalf("Enqueue() err = %v", err)
+	}
+
+	cancelled := make(chan struct{})
+	handler := func(ctx context.Context, job api.DequeueResponse) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}
+	cs := c.NewConsumer("t1", "c1", handler, WithWaitTimeout(10*time.Millisecond), WithLeaseExtension(10*time.Millisecond))
+	go func() { _ = cs.Run(ctx) }()
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler context was not cancelled on a lost lease")
+	}
+	cancel()
+
+	acked, nacked := f.settled()
+	if len(acked) != 0 || len(nacked) != 0 {
+		t.Errorf("job with a lost lease was settled, acked = %v, nacked = %v", acked, nacked)
+	}
+}
+
+func TestConsumer_Run_Invalid(t *testing.T) {
+	cs := New("http://localhost").NewConsumer("", "c1", nil)
+	if err := cs.Run(context.Background()); !errors.Is(err, api.ErrorInvalidRequest) {
+		t.Errorf("Run() err = %v, want %v", err, api.ErrorInvalidRequest)
+	}
+}
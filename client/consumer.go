@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+)
+
+const (
+	defaultWaitTimeout   = 30 * time.Second
+	defaultExtendEvery   = 10 * time.Second
+	defaultRetryInterval = time.Second
+	settleTimeout        = 10 * time.Second // settleTimeout: timeout of the ack or nack of a handled job, they outlive the consumer context
+)
+
+type (
+	// Handler : Processes a job, the job is acked if it returns nil and nacked otherwise
+	Handler func(ctx context.Context, job api.DequeueResponse) error
+
+	// Consumer : Runs goroutines dequeuing the jobs of a topic and passing them to a Handler.
+	// The lease of a job is extended while its handler runs, the handler context is cancelled if the lease is lost
+	Consumer struct {
+		c        *Client
+		topic    string
+		consumer string
+		handler  Handler
+
+		concurrency   int           // concurrency: number of goroutines handling jobs
+		waitTimeout   time.Duration // waitTimeout: long polling timeout of the dequeue calls
+		extendEvery   time.Duration // extendEvery: interval of the lease extensions, zero disables them
+		retryInterval time.Duration // retryInterval: pause after a failed dequeue
+		logger        commons.Logger
+	}
+
+	ConsumerOption = func(cs *Consumer)
+)
+
+// WithConcurrency : Handles up to n jobs at once, defaults to 1
+func WithConcurrency(n int) ConsumerOption {
+	return func(cs *Consumer) {
+		if n > 0 {
+			cs.concurrency = n
+		}
+	}
+}
+
+// WithWaitTimeout : Long polling timeout of the dequeue calls, defaults to 30s
+func WithWaitTimeout(d time.Duration) ConsumerOption {
+	return func(cs *Consumer) {
+		cs.waitTimeout = d
+	}
+}
+
+// WithLeaseExtension : Extends the lease of a job every d while it is handled, it must be shorter than the lease
+// of the worker. Defaults to 10s, zero disables the extensions
+func WithLeaseExtension(d time.Duration) ConsumerOption {
+	return func(cs *Consumer) {
+		cs.extendEvery = d
+	}
+}
+
+// WithRetryInterval : Pause after a failed dequeue, defaults to 1s
+func WithRetryInterval(d time.Duration) ConsumerOption {
+	return func(cs *Consumer) {
+		cs.retryInterval = d
+	}
+}
+
+func WithLogger(logger commons.Logger) ConsumerOption {
+	return func(cs *Consumer) {
+		cs.logger = logger
+	}
+}
+
+// NewConsumer : Returns a consumer of the topic identified as consumer towards the worker
+func (c *Client) NewConsumer(topic, consumer string, handler Handler, opts ...ConsumerOption) *Consumer {
+	cs := &Consumer{
+		c:             c,
+		topic:         topic,
+		consumer:      consumer,
+		handler:       handler,
+		concurrency:   1,
+		waitTimeout:   defaultWaitTimeout,
+		extendEvery:   defaultExtendEvery,
+		retryInterval: defaultRetryInterval,
+		logger:        &commons.DefaultLogger{},
+	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+	return cs
+}
+
+// Run : Consumes the topic until ctx is done, it returns once the jobs being handled are settled
+func (cs *Consumer) Run(ctx context.Context) error {
+	if cs.topic == "" || cs.consumer == "" || cs.handler == nil {
+		return fmt.Errorf("%w: topic, consumer and handler are required", api.ErrorInvalidRequest)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < cs.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cs.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (cs *Consumer) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := cs.c.Dequeue(ctx, api.DequeueRequest{Topic: cs.topic, Consumer: cs.consumer, WaitTimeout: cs.waitTimeout})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			cs.logger.Error(err, "dequeue failed topic=%s consumer=%s", cs.topic, cs.consumer)
+			select {
+			case <-ctx.Done():
+			case <-time.After(cs.retryInterval):
+			}
+			continue
+		}
+		if job.JobId == 0 {
+			continue
+		}
+		cs.handle(ctx, job)
+	}
+}
+
+// handle : Runs the handler while extending the lease, then acks or nacks the job
+func (cs *Consumer) handle(ctx context.Context, job api.DequeueResponse) {
+	hctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	lost := make(chan struct{})
+	go func() {
+		if cs.keepLease(hctx, job.JobId, done) {
+			close(lost)
+			cancel()
+		}
+	}()
+
+	err := cs.run(hctx, job)
+	close(done)
+
+	select {
+	case <-lost:
+		// the job was re-queued, the outcome of the handler can not be recorded anymore
+		return
+	default:
+	}
+
+	sctx, scancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer scancel()
+	req := api.LeaseRequest{JobId: job.JobId, Consumer: cs.consumer}
+	if err == nil {
+		_, err = cs.c.Ack(sctx, api.AckRequest{JobId: job.JobId, Consumer: cs.consumer})
+		if err != nil {
+			cs.logger.Error(err, "ack failed job=%d", job.JobId)
+		}
+		return
+	}
+
+	cs.logger.Debug("handler failed job=%d err=%s", job.JobId, err.Error())
+	if _, err = cs.c.Nack(sctx, req); err != nil && !errors.Is(err, api.ErrorNotSupported) {
+		cs.logger.Error(err, "nack failed job=%d", job.JobId)
+	}
+}
+
+// run : Calls the handler, a panic is turned into an error so that the job is nacked
+func (cs *Consumer) run(ctx context.Context, job api.DequeueResponse) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return cs.handler(ctx, job)
+}
+
+// keepLease : Extends the lease of the job until done is closed, it returns true if the lease was lost
+func (cs *Consumer) keepLease(ctx context.Context, jobId int64, done <-chan struct{}) bool {
+	if cs.extendEvery <= 0 {
+		return false
+	}
+	ticker := time.NewTicker(cs.extendEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+
+		_, err := cs.c.ExtendLease(ctx, api.LeaseRequest{JobId: jobId, Consumer: cs.consumer})
+		switch {
+		case err == nil:
+		case errors.Is(err, api.ErrorLeaseExceeded), errors.Is(err, api.ErrorWrongConsumer),
+			errors.Is(err, api.ErrorAlreadyAcked), errors.Is(err, api.ErrorJobNotPresent):
+			cs.logger.Warn("lease lost job=%d err=%s", jobId, err.Error())
+			return true
+		case errors.Is(err, api.ErrorNotSupported):
+			return false
+		default:
+			// a transient failure, the next tick retries before the lease expires
+			if ctx.Err() == nil {
+				cs.logger.Error(err, "extend lease failed job=%d", jobId)
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/hextechpal/prio/client
+
+go 1.19
+
+require github.com/hextechpal/prio/core v0.0.0-20221125151452-105fca04192c
+
+require github.com/google/uuid v1.3.0 // indirect
+
+replace github.com/hextechpal/prio/core => ../core
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/go-zookeeper/zk"
+)
+
+const (
+	pinRoot    = "/%s/pins"
+	pinNode    = "/%s/pins/%s"
+	cordonRoot = "/%s/cordons"
+	cordonNode = "/%s/cordons/%s"
+)
+
+// Admin : Manages the manual assignment overrides of a namespace.
+// Pins and cordons are stored in zookeeper and honored by the leader on the next re-balance
+type Admin struct {
+	conn      *zk.Conn
+	namespace string
+	acl       []zk.ACL
+}
+
+// NewAdmin : Creates an admin for the namespace on an already connected (and authenticated) zookeeper session.
+// It does not register itself as a member so it can be used by tools outside the worker pool
+func NewAdmin(conn *zk.Conn, namespace string, acl []zk.ACL) *Admin {
+	if acl == nil {
+		acl = zk.WorldACL(zk.PermAll)
+	}
+	return &Admin{conn: conn, namespace: namespace, acl: acl}
+}
+
+// Pin : Assigns the topic to the given worker only, the worker stops receiving any unpinned topic
+func (a *Admin) Pin(topic, workerId string) error {
+	if err := a.ensure(fmt.Sprintf(pinRoot, a.namespace)); err != nil {
+		return err
+	}
+
+	// pins are re-created instead of updated so that the leader's children watch fires
+	err := a.Unpin(topic)
+	if err != nil {
+		return err
+	}
+	_, err = a.conn.Create(fmt.Sprintf(pinNode, a.namespace, topic), []byte(workerId), 0, a.acl)
+	return err
+}
+
+// Unpin : Removes the pin for the topic, it is a no-op if the topic is not pinned
+func (a *Admin) Unpin(topic string) error {
+	err := a.conn.Delete(fmt.Sprintf(pinNode, a.namespace, topic), -1)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	return nil
+}
+
+// Pins : Returns the pinned topics mapped to the worker they are pinned to
+func (a *Admin) Pins() (map[string]string, error) {
+	topics, _, err := a.conn.Children(fmt.Sprintf(pinRoot, a.namespace))
+	if err != nil {
+		if err == zk.ErrNoNode {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	pins := make(map[string]string)
+	for _, topic := range topics {
+		data, _, err := a.conn.Get(fmt.Sprintf(pinNode, a.namespace, topic))
+		if err == zk.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pins[topic] = string(data)
+	}
+	return pins, nil
+}
+
+// Cordon : Excludes the worker from the assignment, all its topics are moved to the other workers
+func (a *Admin) Cordon(workerId string) error {
+	if err := a.ensure(fmt.Sprintf(cordonRoot, a.namespace)); err != nil {
+		return err
+	}
+
+	_, err := a.conn.Create(fmt.Sprintf(cordonNode, a.namespace, workerId), []byte{}, 0, a.acl)
+	if err != nil && err != zk.ErrNodeExists {
+		return err
+	}
+	return nil
+}
+
+// Uncordon : Makes the worker eligible for assignment again, it is a no-op if the worker is not cordoned
+func (a *Admin) Uncordon(workerId string) error {
+	err := a.conn.Delete(fmt.Sprintf(cordonNode, a.namespace, workerId), -1)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	return nil
+}
+
+// Cordons : Returns the set of cordoned workers
+func (a *Admin) Cordons() (map[string]bool, error) {
+	workers, _, err := a.conn.Children(fmt.Sprintf(cordonRoot, a.namespace))
+	if err != nil {
+		if err == zk.ErrNoNode {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+	cordons := make(map[string]bool)
+	for _, worker := range workers {
+		cordons[worker] = true
+	}
+	return cordons, nil
+}
+
+func (a *Admin) ensure(path string) error {
+	_, err := a.conn.Create(path, []byte{}, 0, a.acl)
+	if err != nil && err != zk.ErrNodeExists {
+		return err
+	}
+	return nil
+}
@@ -18,6 +18,9 @@ type Engine interface {
 	// Dequeue : Picks the top priority job from the given topic and returns empty if no job is present
 	Dequeue(ctx context.Context, req DequeueRequest) (DequeueResponse, error)
 
+	// GetJob : Returns the job with its current status, fails with ErrorJobNotPresent if the job does not exist or was purged
+	GetJob(ctx context.Context, req GetJobRequest) (GetJobResponse, error)
+
 	// Ack : Acknowledge a claimed jon, job is marked as complete once the acknowledgement is received
 	// If consumer do not ack the jobId after a fixed amount of time (10sec) for mysql engine
 	// the job will be moved back to pending state and is available to deque again based on priority
@@ -28,3 +31,33 @@ type Engine interface {
 	// ReQueue : Requeue operation runs periodically and move the unacked jobs to the pending queue again
 	ReQueue(ctx context.Context, req RequeueRequest) (RequeueResponse, error)
 }
+
+// Purger : Implemented by the engines able to remove completed jobs, the worker owning a topic
+// purges its completed jobs periodically if a retention is configured
+type Purger interface {
+	// Purge : Archives or deletes at most BatchSize jobs of the topic completed before CompletedBefore
+	Purge(ctx context.Context, req PurgeRequest) (PurgeResponse, error)
+}
+
+// Inspector : Implemented by the engines able to report the state of a topic, used for the queue depth metrics
+type Inspector interface {
+	// PendingCount : Returns the number of jobs of the topic waiting to be dequeued
+	PendingCount(ctx context.Context, topic string) (int64, error)
+}
+
+// Leaser : Implemented by the engines able to manage the lease of a claimed job, consumers of long running jobs
+// extend the lease so that the job is not re-queued and give back the jobs they fail to process
+type Leaser interface {
+	// ExtendLease : Restarts the lease of a job claimed by the consumer, it fails like Ack if the job is not claimed by the consumer
+	ExtendLease(ctx context.Context, req LeaseRequest) (LeaseResponse, error)
+
+	// Nack : Moves a job claimed by the consumer back to pending right away, it fails like Ack if the job is not claimed by the consumer
+	Nack(ctx context.Context, req LeaseRequest) (LeaseResponse, error)
+}
+
+// Notifier : Implemented by the engines able to signal pending jobs across workers, the long polling dequeue calls
+// of every worker are woken by the enqueues on any worker instead of waiting for their next poll
+type Notifier interface {
+	// WaitForJob : Blocks until a job may be pending for the topic or the context is done
+	WaitForJob(ctx context.Context, topic string) error
+}
@@ -1,6 +1,10 @@
 package api
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net/http"
+)
 
 var (
 	ErrorGeneral = errors.New("something went wrong try again")
@@ -10,4 +14,81 @@ var (
 	ErrorAlreadyAcked   = errors.New("job already acked")
 	ErrorWrongConsumer  = errors.New("job claimed by a different consumer")
 	ErrorLeaseExceeded  = errors.New("lease time exceeded")
+
+	ErrorInvalidRequest = errors.New("invalid request")
+	ErrorNotSupported   = errors.New("operation not supported by the engine")
 )
+
+// grpc status codes, the values of grpc codes.Code which core does not depend on
+const (
+	grpcCanceled           uint32 = 1
+	grpcInvalidArgument    uint32 = 3
+	grpcDeadlineExceeded   uint32 = 4
+	grpcNotFound           uint32 = 5
+	grpcPermissionDenied   uint32 = 7
+	grpcFailedPrecondition uint32 = 9
+	grpcAborted            uint32 = 10
+	grpcUnimplemented      uint32 = 12
+	grpcUnavailable        uint32 = 14
+)
+
+// errorCodes : Stable codes of the errors, they identify an error across the wire, along with the http status
+// and the grpc code the apis answer with. Errors wrapping none of them are GENERAL, 503 and Unavailable
+// as the engine or zookeeper is unavailable
+var errorCodes = []struct {
+	err        error
+	code       string
+	httpStatus int
+	grpcCode   uint32
+}{
+	{ErrorJobNotAcquired, "JOB_NOT_ACQUIRED", http.StatusConflict, grpcAborted},
+	{ErrorJobNotPresent, "JOB_NOT_PRESENT", http.StatusNotFound, grpcNotFound},
+	{ErrorAlreadyAcked, "ALREADY_ACKED", http.StatusConflict, grpcFailedPrecondition},
+	{ErrorWrongConsumer, "WRONG_CONSUMER", http.StatusConflict, grpcPermissionDenied},
+	{ErrorLeaseExceeded, "LEASE_EXCEEDED", http.StatusGone, grpcFailedPrecondition},
+	{ErrorInvalidRequest, "INVALID_REQUEST", http.StatusBadRequest, grpcInvalidArgument},
+	{ErrorNotSupported, "NOT_SUPPORTED", http.StatusNotImplemented, grpcUnimplemented},
+	{ErrorGeneral, "GENERAL", http.StatusServiceUnavailable, grpcUnavailable},
+	{context.Canceled, "GENERAL", http.StatusServiceUnavailable, grpcCanceled},
+	{context.DeadlineExceeded, "GENERAL", http.StatusServiceUnavailable, grpcDeadlineExceeded},
+}
+
+// ErrorCode : Returns the code of the error, errors wrapping none of the api errors are GENERAL
+func ErrorCode(err error) string {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code
+		}
+	}
+	return "GENERAL"
+}
+
+// ErrorForCode : Returns the api error of the code, unknown codes are ErrorGeneral
+func ErrorForCode(code string) error {
+	for _, ec := range errorCodes {
+		if ec.code == code {
+			return ec.err
+		}
+	}
+	return ErrorGeneral
+}
+
+// HTTPStatus : Returns the http status answered for the error
+func HTTPStatus(err error) int {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.httpStatus
+		}
+	}
+	return http.StatusServiceUnavailable
+}
+
+// GRPCCode : Returns the grpc code answered for the error, convert it with codes.Code
+func GRPCCode(err error) uint32 {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return ec.grpcCode
+		}
+	}
+	return grpcUnavailable
+}
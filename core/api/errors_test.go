@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "api error", err: ErrorWrongConsumer, want: "WRONG_CONSUMER"},
+		{name: "wrapped", err: fmt.Errorf("%w: topic is required", ErrorInvalidRequest), want: "INVALID_REQUEST"},
+		{name: "other", err: errors.New("connection refused"), want: "GENERAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ErrorCode(tt.err)
+			if got != tt.want {
+				t.Fatalf("ErrorCode() got = %s, want %s", got, tt.want)
+			}
+			if tt.want != "GENERAL" && !errors.Is(tt.err, ErrorForCode(got)) {
+				t.Errorf("ErrorForCode(%s) got = %v, want %v", got, ErrorForCode(got), tt.err)
+			}
+		})
+	}
+
+	if err := ErrorForCode("UNKNOWN"); err != ErrorGeneral {
+		t.Errorf("ErrorForCode() of an unknown code got = %v, want %v", err, ErrorGeneral)
+	}
+}
+
+func TestHTTPStatus_GRPCCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   uint32
+	}{
+		{name: "Invalid request", err: fmt.Errorf("%w: topic is required", ErrorInvalidRequest), wantStatus: http.StatusBadRequest, wantCode: grpcInvalidArgument},
+		{name: "Job not acquired", err: ErrorJobNotAcquired, wantStatus: http.StatusConflict, wantCode: grpcAborted},
+		{name: "Not supported", err: ErrorNotSupported, wantStatus: http.StatusNotImplemented, wantCode: grpcUnimplemented},
+		{name: "Lease exceeded", err: ErrorLeaseExceeded, wantStatus: http.StatusGone, wantCode: grpcFailedPrecondition},
+		{name: "Canceled", err: context.Canceled, wantStatus: http.StatusServiceUnavailable, wantCode: grpcCanceled},
+		{name: "Other", err: errors.New("connection refused"), wantStatus: http.StatusServiceUnavailable, wantCode: grpcUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTTPStatus(tt.err); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() got = %d, want %d", got, tt.wantStatus)
+			}
+			if got := GRPCCode(tt.err); got != tt.wantCode {
+				t.Errorf("GRPCCode() got = %d, want %d", got, tt.wantCode)
+			}
+		})
+	}
+}
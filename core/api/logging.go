@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/hextechpal/prio/core/commons"
+)
+
+type loggingEngine struct {
+	Engine
+	logger commons.Logger
+}
+
+// Logging : Logs every engine call with its arguments, outcome and duration, failed calls are logged as errors
+func Logging(logger commons.Logger) Middleware {
+	return func(e Engine) Engine {
+		return &loggingEngine{Engine: e, logger: logger}
+	}
+}
+
+func (l *loggingEngine) log(op string, start time.Time, err error, format string, v ...any) {
+	v = append([]any{op}, v...)
+	v = append(v, time.Since(start).Milliseconds())
+	if err != nil {
+		l.logger.Error(err, "engine op=%s "+format+" duration_ms=%d", v...)
+		return
+	}
+	l.logger.Debug("engine op=%s "+format+" duration_ms=%d", v...)
+}
+
+func (l *loggingEngine) GetTopics(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	topics, err := l.Engine.GetTopics(ctx)
+	l.log("get_topics", start, err, "count=%d", len(topics))
+	return topics, err
+}
+
+func (l *loggingEngine) RegisterTopic(ctx context.Context, req RegisterTopicRequest) (RegisterTopicResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.RegisterTopic(ctx, req)
+	l.log("register_topic", start, err, "topic=%s", req.Name)
+	return res, err
+}
+
+func (l *loggingEngine) Enqueue(ctx context.Context, req EnqueueRequest) (EnqueueResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.Enqueue(ctx, req)
+	l.log("enqueue", start, err, "topic=%s priority=%d payload_bytes=%d job=%d", req.Topic, req.Priority, len(req.Payload), res.JobId)
+	return res, err
+}
+
+func (l *loggingEngine) Dequeue(ctx context.Context, req DequeueRequest) (DequeueResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.Dequeue(ctx, req)
+	l.log("dequeue", start, err, "topic=%s consumer=%s job=%d", req.Topic, req.Consumer, res.JobId)
+	return res, err
+}
+
+func (l *loggingEngine) GetJob(ctx context.Context, req GetJobRequest) (GetJobResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.GetJob(ctx, req)
+	l.log("get_job", start, err, "job=%d status=%s", req.JobId, res.Status)
+	return res, err
+}
+
+func (l *loggingEngine) Ack(ctx context.Context, req AckRequest) (AckResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.Ack(ctx, req)
+	l.log("ack", start, err, "job=%d consumer=%s acked=%v", req.JobId, req.Consumer, res.Acked)
+	return res, err
+}
+
+func (l *loggingEngine) ReQueue(ctx context.Context, req RequeueRequest) (RequeueResponse, error) {
+	start := time.Now()
+	res, err := l.Engine.ReQueue(ctx, req)
+	l.log("requeue", start, err, "topic=%s count=%d", req.Topic, res.Count)
+	return res, err
+}
+
+func (l *loggingEngine) Purge(ctx context.Context, req PurgeRequest) (PurgeResponse, error) {
+	start := time.Now()
+	res, err := purge(ctx, l.Engine, req)
+	l.log("purge", start, err, "topic=%s count=%d", req.Topic, res.Count)
+	return res, err
+}
+
+func (l *loggingEngine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	start := time.Now()
+	count, err := pendingCount(ctx, l.Engine, topic)
+	l.log("pending_count", start, err, "topic=%s count=%d", topic, count)
+	return count, err
+}
+
+func (l *loggingEngine) ExtendLease(ctx context.Context, req LeaseRequest) (LeaseResponse, error) {
+	start := time.Now()
+	res, err := extendLease(ctx, l.Engine, req)
+	l.log("extend_lease", start, err, "job=%d consumer=%s", req.JobId, req.Consumer)
+	return res, err
+}
+
+func (l *loggingEngine) Nack(ctx context.Context, req LeaseRequest) (LeaseResponse, error) {
+	start := time.Now()
+	res, err := nack(ctx, l.Engine, req)
+	l.log("nack", start, err, "job=%d consumer=%s", req.JobId, req.Consumer)
+	return res, err
+}
+
+// WaitForJob : Forwarded as is, the call blocks until a job is enqueued
+func (l *loggingEngine) WaitForJob(ctx context.Context, topic string) error {
+	return waitForJob(ctx, l.Engine, topic)
+}
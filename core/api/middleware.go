@@ -0,0 +1,55 @@
+package api
+
+import "context"
+
+// Middleware : Decorates an engine with a cross-cutting concern. The returned engine also implements Purger, Inspector, Leaser and Notifier,
+// forwarding to the decorated engine if it implements them. The calls fail with ErrorNotSupported otherwise
+type Middleware func(Engine) Engine
+
+// Chain : Wraps the engine with the middlewares, the first middleware is the outermost and sees the calls first
+func Chain(e Engine, middlewares ...Middleware) Engine {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		e = middlewares[i](e)
+	}
+	return e
+}
+
+// purge : Forwards the purge to the engine if it is a Purger
+func purge(ctx context.Context, e Engine, req PurgeRequest) (PurgeResponse, error) {
+	if p, ok := e.(Purger); ok {
+		return p.Purge(ctx, req)
+	}
+	return PurgeResponse{}, ErrorNotSupported
+}
+
+// pendingCount : Forwards to the engine if it is an Inspector
+func pendingCount(ctx context.Context, e Engine, topic string) (int64, error) {
+	if i, ok := e.(Inspector); ok {
+		return i.PendingCount(ctx, topic)
+	}
+	return 0, ErrorNotSupported
+}
+
+// extendLease : Forwards to the engine if it is a Leaser
+func extendLease(ctx context.Context, e Engine, req LeaseRequest) (LeaseResponse, error) {
+	if l, ok := e.(Leaser); ok {
+		return l.ExtendLease(ctx, req)
+	}
+	return LeaseResponse{}, ErrorNotSupported
+}
+
+// nack : Forwards to the engine if it is a Leaser
+func nack(ctx context.Context, e Engine, req LeaseRequest) (LeaseResponse, error) {
+	if l, ok := e.(Leaser); ok {
+		return l.Nack(ctx, req)
+	}
+	return LeaseResponse{}, ErrorNotSupported
+}
+
+// waitForJob : Forwards to the engine if it is a Notifier
+func waitForJob(ctx context.Context, e Engine, topic string) error {
+	if n, ok := e.(Notifier); ok {
+		return n.WaitForJob(ctx, topic)
+	}
+	return ErrorNotSupported
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/commons"
+)
+
+// fakeEngine : Fails the calls with the queued errors, then succeeds
+type fakeEngine struct {
+	Engine
+	errs  []error
+	calls int
+}
+
+func (f *fakeEngine) next() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeEngine) Enqueue(_ context.Context, _ EnqueueRequest) (EnqueueResponse, error) {
+	if err := f.next(); err != nil {
+		return EnqueueResponse{}, err
+	}
+	return EnqueueResponse{JobId: 1}, nil
+}
+
+func (f *fakeEngine) Dequeue(_ context.Context, _ DequeueRequest) (DequeueResponse, error) {
+	if err := f.next(); err != nil {
+		return DequeueResponse{}, err
+	}
+	return DequeueResponse{JobId: 1}, nil
+}
+
+func (f *fakeEngine) Ack(_ context.Context, _ AckRequest) (AckResponse, error) {
+	if err := f.next(); err != nil {
+		return AckResponse{}, err
+	}
+	return AckResponse{Acked: true}, nil
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(e Engine) Engine {
+			order = append(order, name)
+			return e
+		}
+	}
+
+	Chain(&fakeEngine{}, record("outer"), record("inner"))
+	if len(order) != 2 || order[0] != "inner" || order[1] != "outer" {
+		t.Errorf("Chain() wrapped in order %v, want [inner outer]", order)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	e := Chain(&fakeEngine{}, Validation(ValidationConfig{MaxPayloadBytes: 4}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr bool
+	}{
+		{name: "Valid enqueue", call: func() error {
+			_, err := e.Enqueue(ctx, EnqueueRequest{Topic: "t1", Payload: []byte("1234")})
+			return err
+		}},
+		{name: "Empty topic", wantErr: true, call: func() error {
+			_, err := e.Enqueue(ctx, EnqueueRequest{Payload: []byte("1")})
+			return err
+		}},
+		{name: "Payload too large", wantErr: true, call: func() error {
+			_, err := e.Enqueue(ctx, EnqueueRequest{Topic: "t1", Payload: []byte("12345")})
+			return err
+		}},
+		{name: "Empty header name", wantErr: true, call: func() error {
+			_, err := e.Enqueue(ctx, EnqueueRequest{Topic: "t1", Headers: map[string]string{"": "v"}})
+			return err
+		}},
+		{name: "Empty consumer", wantErr: true, call: func() error {
+			_, err := e.Dequeue(ctx, DequeueRequest{Topic: "t1"})
+			return err
+		}},
+		{name: "Invalid job id", wantErr: true, call: func() error {
+			_, err := e.Ack(ctx, AckRequest{Consumer: "c1"})
+			return err
+		}},
+		{name: "Invalid get job id", wantErr: true, call: func() error {
+			_, err := e.GetJob(ctx, GetJobRequest{})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.wantErr && !errors.Is(err, ErrorInvalidRequest) {
+				t.Errorf("err=%v, want %v", err, ErrorInvalidRequest)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("err=%v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	transient := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "Success", wantCalls: 1},
+		{name: "Transient then success", errs: []error{transient, transient}, wantCalls: 3},
+		{name: "Attempts exhausted", errs: []error{transient, transient, transient}, wantCalls: 3, wantErr: transient},
+		{name: "Contract error", errs: []error{ErrorJobNotPresent}, wantCalls: 1, wantErr: ErrorJobNotPresent},
+		{name: "Unknown error", errs: []error{errDuplicate}, wantCalls: 1, wantErr: errDuplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeEngine{errs: tt.errs}
+			e := Chain(f, Retry(RetryConfig{Attempts: 3, Backoff: time.Millisecond}))
+
+			res, err := e.Dequeue(context.Background(), DequeueRequest{Topic: "t1", Consumer: "c1"})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && res.JobId != 1) {
+				t.Errorf("Dequeue() job=%d err=%v, want err=%v", res.JobId, err, tt.wantErr)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("Dequeue() calls=%d, want %d", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetry_NotIdempotent(t *testing.T) {
+	transient := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	f := &fakeEngine{errs: []error{transient, transient}}
+	e := Chain(f, Retry(RetryConfig{Attempts: 3, Backoff: time.Millisecond}))
+
+	if _, err := e.Enqueue(context.Background(), EnqueueRequest{Topic: "t1"}); !errors.Is(err, transient) || f.calls != 1 {
+		t.Errorf("Enqueue() calls=%d err=%v, want a single call", f.calls, err)
+	}
+	if _, err := e.Ack(context.Background(), AckRequest{JobId: 1, Consumer: "c1"}); !errors.Is(err, transient) || f.calls != 2 {
+		t.Errorf("Ack() calls=%d err=%v, want a single call", f.calls, err)
+	}
+}
+
+var errDuplicate = errors.New("duplicate entry 't1' for key 'topics.name'")
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection reset", err: fmt.Errorf("read tcp: %w", syscall.ECONNRESET), want: true},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, want: true},
+		{name: "context canceled", err: context.Canceled},
+		{name: "contract error", err: ErrorWrongConsumer},
+		{name: "unknown error", err: errDuplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestMiddleware_Leaser(t *testing.T) {
+	e := Chain(&fakeEngine{}, Logging(&commons.DefaultLogger{}), Validation(ValidationConfig{}), Retry(RetryConfig{}))
+	l, ok := e.(Leaser)
+	if !ok {
+		t.Fatalf("Chain() engine should implement Leaser")
+	}
+
+	if _, err := l.ExtendLease(context.Background(), LeaseRequest{JobId: 1, Consumer: "c1"}); !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("ExtendLease() err=%v, want %v", err, ErrorNotSupported)
+	}
+	if _, err := l.Nack(context.Background(), LeaseRequest{JobId: 1}); !errors.Is(err, ErrorInvalidRequest) {
+		t.Errorf("Nack() err=%v, want %v", err, ErrorInvalidRequest)
+	}
+}
+
+func TestMiddleware_Notifier(t *testing.T) {
+	e := Chain(&fakeEngine{}, Logging(&commons.DefaultLogger{}), Validation(ValidationConfig{}), Retry(RetryConfig{}))
+	n, ok := e.(Notifier)
+	if !ok {
+		t.Fatalf("Chain() engine should implement Notifier")
+	}
+	if err := n.WaitForJob(context.Background(), "t1"); !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("WaitForJob() err=%v, want %v", err, ErrorNotSupported)
+	}
+}
+
+func TestMiddleware_Purger(t *testing.T) {
+	e := Chain(&fakeEngine{}, Logging(&commons.DefaultLogger{}), Validation(ValidationConfig{}), Retry(RetryConfig{}))
+	p, ok := e.(Purger)
+	if !ok {
+		t.Fatalf("Chain() engine should implement Purger")
+	}
+	if _, err := p.Purge(context.Background(), PurgeRequest{Topic: "t1"}); !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("Purge() err=%v, want %v", err, ErrorNotSupported)
+	}
+}
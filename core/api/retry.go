@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+type (
+	// RetryConfig : Retry policy of the Retry middleware
+	RetryConfig struct {
+		Attempts   int                  // Attempts: total attempts including the first call, defaults to 3
+		Backoff    time.Duration        // Backoff: delay before the first retry, doubled on every retry, defaults to 50ms
+		MaxBackoff time.Duration        // MaxBackoff: upper bound of the delay, defaults to 1s
+		Retryable  func(err error) bool // Retryable: reports transient errors, defaults to IsTransient
+	}
+
+	retryingEngine struct {
+		Engine
+		config RetryConfig
+	}
+)
+
+// IsTransient : Reports whether the error is a connection failure which may go away on retry, a broken driver connection,
+// a reset or refused connection or a network timeout. Other errors are permanent, the sql engines extend it with their
+// deadlock and lock timeout errors
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Retry : Retries the calls failing with a transient error with exponential backoff. RegisterTopic, Enqueue, Ack and Nack
+// are not idempotent, a call failing after its commit would be applied twice or fail on retry, so they are never retried.
+// A Dequeue failing after its claim is retried, the lost claim is re-queued once its lease expires
+func Retry(config RetryConfig) Middleware {
+	if config.Attempts <= 0 {
+		config.Attempts = 3
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 50 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Second
+	}
+	if config.Retryable == nil {
+		config.Retryable = IsTransient
+	}
+	return func(e Engine) Engine {
+		return &retryingEngine{Engine: e, config: config}
+	}
+}
+
+// do : Calls fn until it succeeds, fails with a non-transient error, the attempts run out or the context is done
+func (r *retryingEngine) do(ctx context.Context, fn func() error) error {
+	backoff := r.config.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= r.config.Attempts || !r.config.Retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+func (r *retryingEngine) GetTopics(ctx context.Context) (topics []string, err error) {
+	err = r.do(ctx, func() error {
+		topics, err = r.Engine.GetTopics(ctx)
+		return err
+	})
+	return topics, err
+}
+
+func (r *retryingEngine) Dequeue(ctx context.Context, req DequeueRequest) (res DequeueResponse, err error) {
+	err = r.do(ctx, func() error {
+		res, err = r.Engine.Dequeue(ctx, req)
+		return err
+	})
+	return res, err
+}
+
+func (r *retryingEngine) GetJob(ctx context.Context, req GetJobRequest) (res GetJobResponse, err error) {
+	err = r.do(ctx, func() error {
+		res, err = r.Engine.GetJob(ctx, req)
+		return err
+	})
+	return res, err
+}
+
+func (r *retryingEngine) ReQueue(ctx context.Context, req RequeueRequest) (res RequeueResponse, err error) {
+	err = r.do(ctx, func() error {
+		res, err = r.Engine.ReQueue(ctx, req)
+		return err
+	})
+	return res, err
+}
+
+func (r *retryingEngine) Purge(ctx context.Context, req PurgeRequest) (res PurgeResponse, err error) {
+	err = r.do(ctx, func() error {
+		res, err = purge(ctx, r.Engine, req)
+		return err
+	})
+	return res, err
+}
+
+func (r *retryingEngine) PendingCount(ctx context.Context, topic string) (count int64, err error) {
+	err = r.do(ctx, func() error {
+		count, err = pendingCount(ctx, r.Engine, topic)
+		return err
+	})
+	return count, err
+}
+
+func (r *retryingEngine) ExtendLease(ctx context.Context, req LeaseRequest) (res LeaseResponse, err error) {
+	err = r.do(ctx, func() error {
+		res, err = extendLease(ctx, r.Engine, req)
+		return err
+	})
+	return res, err
+}
+
+// Nack : Not retried, see Retry
+func (r *retryingEngine) Nack(ctx context.Context, req LeaseRequest) (LeaseResponse, error) {
+	return nack(ctx, r.Engine, req)
+}
+
+// WaitForJob : Forwarded as is, the call blocks until a job is enqueued
+func (r *retryingEngine) WaitForJob(ctx context.Context, topic string) error {
+	return waitForJob(ctx, r.Engine, topic)
+}
@@ -1,8 +1,46 @@
 package api
 
+import (
+	"fmt"
+	"time"
+)
+
+// JobStatus : Lifecycle state of a job, marshalled by name
+type JobStatus int
+
+const (
+	JobPending   JobStatus = iota // JobPending: the job waits to be dequeued
+	JobClaimed                    // JobClaimed: the job is dequeued and waits for its ack
+	JobCompleted                  // JobCompleted: the job is acked
+)
+
+var jobStatusNames = []string{"PENDING", "CLAIMED", "COMPLETED"}
+
+func (s JobStatus) String() string {
+	if s < 0 || int(s) >= len(jobStatusNames) {
+		return fmt.Sprintf("JobStatus(%d)", int(s))
+	}
+	return jobStatusNames[s]
+}
+
+func (s JobStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *JobStatus) UnmarshalText(text []byte) error {
+	for i, name := range jobStatusNames {
+		if name == string(text) {
+			*s = JobStatus(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown job status %s", text)
+}
+
 type RegisterTopicRequest struct {
 	Name        string
 	Description string
+	Placement   map[string]string // Placement: labels a worker must have to be assigned the topic
 }
 
 type RegisterTopicResponse struct{}
@@ -11,6 +49,7 @@ type EnqueueRequest struct {
 	Topic    string
 	Priority int32
 	Payload  []byte
+	Headers  map[string]string // Headers: metadata stored with the job and returned on dequeue, e.g. content-type, tenant or trace context
 }
 
 type EnqueueResponse struct {
@@ -18,8 +57,9 @@ type EnqueueResponse struct {
 }
 
 type DequeueRequest struct {
-	Topic    string
-	Consumer string
+	Topic       string
+	Consumer    string
+	WaitTimeout time.Duration // WaitTimeout: how long the worker waits for a job on an empty topic, zero returns immediately
 }
 
 type DequeueResponse struct {
@@ -27,6 +67,21 @@ type DequeueResponse struct {
 	Topic    string
 	Payload  []byte
 	Priority int32
+	Headers  map[string]string
+}
+
+type GetJobRequest struct {
+	JobId int64
+}
+
+type GetJobResponse struct {
+	JobId     int64
+	Topic     string
+	Payload   []byte
+	Priority  int32
+	Headers   map[string]string
+	Status    JobStatus
+	ClaimedBy string // ClaimedBy: consumer of the last claim, empty if the job was never claimed or was re-queued
 }
 
 type AckRequest struct {
@@ -38,6 +93,15 @@ type AckResponse struct {
 	Acked bool
 }
 
+type LeaseRequest struct {
+	JobId    int64
+	Consumer string
+}
+
+type LeaseResponse struct {
+	Ok bool
+}
+
 type RequeueRequest struct {
 	Topic     string
 	RequeueTs int64
@@ -46,3 +110,14 @@ type RequeueRequest struct {
 type RequeueResponse struct {
 	Count int64
 }
+
+type PurgeRequest struct {
+	Topic           string
+	CompletedBefore int64 // CompletedBefore: jobs completed before this timestamp(ms) are purged
+	Archive         bool  // Archive: move the jobs to the archive instead of deleting them
+	BatchSize       int
+}
+
+type PurgeResponse struct {
+	Count int64
+}
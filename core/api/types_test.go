@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJobStatus_JSON(t *testing.T) {
+	tests := []struct {
+		status JobStatus
+		want   string
+	}{
+		{status: JobPending, want: `"PENDING"`},
+		{status: JobClaimed, want: `"CLAIMED"`},
+		{status: JobCompleted, want: `"COMPLETED"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			data, err := json.Marshal(tt.status)
+			if err != nil || string(data) != tt.want {
+				t.Fatalf("Marshal() got = %s, err=%v, want %s", data, err, tt.want)
+			}
+
+			var got JobStatus
+			if err = json.Unmarshal(data, &got); err != nil || got != tt.status {
+				t.Errorf("Unmarshal() got = %v, err=%v, want %v", got, err, tt.status)
+			}
+		})
+	}
+
+	var s JobStatus
+	if err := json.Unmarshal([]byte(`"UNKNOWN"`), &s); err == nil {
+		t.Errorf("Unmarshal() of an unknown status should fail")
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// ValidationConfig : Limits enforced by the Validation middleware, zero disables a limit
+	ValidationConfig struct {
+		MaxPayloadBytes int // MaxPayloadBytes: max size of an enqueued payload
+	}
+
+	validatingEngine struct {
+		Engine
+		config ValidationConfig
+	}
+)
+
+// Validation : Rejects malformed requests with ErrorInvalidRequest before they reach the engine
+func Validation(config ValidationConfig) Middleware {
+	return func(e Engine) Engine {
+		return &validatingEngine{Engine: e, config: config}
+	}
+}
+
+func invalid(format string, v ...any) error {
+	return fmt.Errorf("%w: %s", ErrorInvalidRequest, fmt.Sprintf(format, v...))
+}
+
+func (v *validatingEngine) RegisterTopic(ctx context.Context, req RegisterTopicRequest) (RegisterTopicResponse, error) {
+	if req.Name == "" {
+		return RegisterTopicResponse{}, invalid("topic is empty")
+	}
+	return v.Engine.RegisterTopic(ctx, req)
+}
+
+func (v *validatingEngine) Enqueue(ctx context.Context, req EnqueueRequest) (EnqueueResponse, error) {
+	if req.Topic == "" {
+		return EnqueueResponse{}, invalid("topic is empty")
+	}
+	if _, ok := req.Headers[""]; ok {
+		return EnqueueResponse{}, invalid("header name is empty")
+	}
+	if v.config.MaxPayloadBytes > 0 && len(req.Payload) > v.config.MaxPayloadBytes {
+		return EnqueueResponse{}, invalid("payload of %d bytes exceeds the limit of %d bytes", len(req.Payload), v.config.MaxPayloadBytes)
+	}
+	return v.Engine.Enqueue(ctx, req)
+}
+
+func (v *validatingEngine) Dequeue(ctx context.Context, req DequeueRequest) (DequeueResponse, error) {
+	if req.Topic == "" {
+		return DequeueResponse{}, invalid("topic is empty")
+	}
+	if req.Consumer == "" {
+		return DequeueResponse{}, invalid("consumer is empty")
+	}
+	if req.WaitTimeout < 0 {
+		return DequeueResponse{}, invalid("wait timeout %s is negative", req.WaitTimeout)
+	}
+	return v.Engine.Dequeue(ctx, req)
+}
+
+func (v *validatingEngine) GetJob(ctx context.Context, req GetJobRequest) (GetJobResponse, error) {
+	if req.JobId <= 0 {
+		return GetJobResponse{}, invalid("job id %d is not valid", req.JobId)
+	}
+	return v.Engine.GetJob(ctx, req)
+}
+
+func (v *validatingEngine) Ack(ctx context.Context, req AckRequest) (AckResponse, error) {
+	if req.JobId <= 0 {
+		return AckResponse{}, invalid("job id %d is not valid", req.JobId)
+	}
+	if req.Consumer == "" {
+		return AckResponse{}, invalid("consumer is empty")
+	}
+	return v.Engine.Ack(ctx, req)
+}
+
+func (v *validatingEngine) ReQueue(ctx context.Context, req RequeueRequest) (RequeueResponse, error) {
+	if req.Topic == "" {
+		return RequeueResponse{}, invalid("topic is empty")
+	}
+	return v.Engine.ReQueue(ctx, req)
+}
+
+func (v *validatingEngine) Purge(ctx context.Context, req PurgeRequest) (PurgeResponse, error) {
+	if req.Topic == "" {
+		return PurgeResponse{}, invalid("topic is empty")
+	}
+	return purge(ctx, v.Engine, req)
+}
+
+func (v *validatingEngine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	if topic == "" {
+		return 0, invalid("topic is empty")
+	}
+	return pendingCount(ctx, v.Engine, topic)
+}
+
+func (v *validatingEngine) ExtendLease(ctx context.Context, req LeaseRequest) (LeaseResponse, error) {
+	if err := validateLease(req); err != nil {
+		return LeaseResponse{}, err
+	}
+	return extendLease(ctx, v.Engine, req)
+}
+
+func (v *validatingEngine) Nack(ctx context.Context, req LeaseRequest) (LeaseResponse, error) {
+	if err := validateLease(req); err != nil {
+		return LeaseResponse{}, err
+	}
+	return nack(ctx, v.Engine, req)
+}
+
+func validateLease(req LeaseRequest) error {
+	if req.JobId <= 0 {
+		return invalid("job id %d is not valid", req.JobId)
+	}
+	if req.Consumer == "" {
+		return invalid("consumer is empty")
+	}
+	return nil
+}
+
+// WaitForJob : Forwarded as is, the call blocks until a job is enqueued
+func (v *validatingEngine) WaitForJob(ctx context.Context, topic string) error {
+	return waitForJob(ctx, v.Engine, topic)
+}
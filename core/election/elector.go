@@ -18,6 +18,7 @@ type (
 		Role        Role
 		Err         error
 		Following   string
+		Leader      string // Leader: candidate id of the current leader at the time of the status update
 	}
 
 	Elector struct {
@@ -31,15 +32,19 @@ type (
 		// Zookeeper channels
 		myCh          <-chan zk.Event // myCh channel watches the events for the znode created for the instance
 		predecessorCh <-chan zk.Event // predecessorCh channel watched for predecessor delete events and trigger a check if deleted
+		leaderCh      <-chan zk.Event // leaderCh channel watches the lowest candidate znode so that followers refresh the leader when it goes away
 
 		// Internal channels for communication
 		triggerElectionCh chan error // triggerElectionCh watches for trigger election events
 		resignCh          chan any   // resignCh informs routine of the resignation and cleanup resource
 		stopCh            chan any   // stopCh stop all the watch routines
 
+		acl    []zk.ACL // acl applied to the candidate znodes
 		once   sync.Once
 		logger commons.Logger
 	}
+
+	Option = func(e *Elector)
 )
 
 const (
@@ -50,10 +55,17 @@ const (
 )
 
 func (s Status) String() string {
-	return fmt.Sprintf("canditateId=%s, role=%d, following=%s, err=%s", s.CandidateId, s.Role, s.Following, s.Err)
+	return fmt.Sprintf("canditateId=%s, role=%d, following=%s, leader=%s, err=%s", s.CandidateId, s.Role, s.Following, s.Leader, s.Err)
+}
+
+// WithACL : Sets the acl used to create the candidate znodes, defaults to world acl
+func WithACL(acl []zk.ACL) Option {
+	return func(e *Elector) {
+		e.acl = acl
+	}
 }
 
-func NewElector(conn *zk.Conn, electionRoot string, logger commons.Logger) (*Elector, error) {
+func NewElector(conn *zk.Conn, electionRoot string, logger commons.Logger, opts ...Option) (*Elector, error) {
 	if conn == nil {
 		return nil, errors.New("conn cannot be nil")
 	}
@@ -67,7 +79,7 @@ func NewElector(conn *zk.Conn, electionRoot string, logger commons.Logger) (*Ele
 		return nil, fmt.Errorf("election root %s should be present", electionRoot)
 	}
 
-	return &Elector{
+	e := &Elector{
 		root:     electionRoot,
 		conn:     conn,
 		statusCh: make(chan Status, 1),
@@ -76,8 +88,14 @@ func NewElector(conn *zk.Conn, electionRoot string, logger commons.Logger) (*Ele
 		resignCh:          make(chan any),
 		stopCh:            make(chan any),
 
+		acl:    zk.WorldACL(zk.PermAll),
 		logger: logger,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
 }
 
 func (e *Elector) Elect(candidateId string) {
@@ -116,6 +134,11 @@ func (e *Elector) Status() chan Status {
 	return e.statusCh
 }
 
+// Leader : Returns the candidate id of the current leader, empty if the election has no candidates
+func (e *Elector) Leader() (string, error) {
+	return currentLeader(e.conn, e.root)
+}
+
 func (e *Elector) Resign() {
 	close(e.resignCh)
 }
@@ -156,7 +179,7 @@ func (e *Elector) initiateElection(candidateId string) Status {
 }
 
 func (e *Elector) findLeader(status *Status) {
-	leader, toFollow, err := e.amILeader()
+	leader, toFollow, lowest, err := e.amILeader()
 	if err != nil {
 		status.Err = err
 		return
@@ -166,10 +189,23 @@ func (e *Elector) findLeader(status *Status) {
 		e.logger.Info("selected as leader, updating status")
 		status.Role = LEADER
 		status.Following = ""
+		status.Leader = status.CandidateId
 		go e.watchLeader()
 		return
 	}
 
+	// the leader is watched on top of the predecessor as a follower further down the line only hears about its predecessor
+	status.Leader, e.leaderCh, err = e.watchLowest(lowest, toFollow)
+	if err == zk.ErrNoNode {
+		e.logger.Info("leader znode=%s deleted meanwhile, finding leader again", lowest)
+		e.findLeader(status)
+		return
+	}
+	if err != nil {
+		status.Err = err
+		return
+	}
+
 	e.logger.Info("setting up follower watch for znode=%s", toFollow)
 	followCh, err := e.setPredecessorWatch(toFollow)
 	if err != nil {
@@ -183,8 +219,7 @@ func (e *Elector) findLeader(status *Status) {
 
 func (e *Elector) nominate(candidateId string) (string, <-chan zk.Event, error) {
 	flags := int32(zk.FlagEphemeral | zk.FlagSequence)
-	acl := zk.WorldACL(zk.PermAll)
-	znode, err := e.conn.Create(strings.Join([]string{e.root, "le_"}, sep), []byte(candidateId), flags, acl)
+	znode, err := e.conn.Create(strings.Join([]string{e.root, "le_"}, sep), []byte(candidateId), flags, e.acl)
 	if err != nil {
 		return "", nil, err
 	}
@@ -200,17 +235,16 @@ func (e *Elector) nominate(candidateId string) (string, <-chan zk.Event, error)
 	return znode, myCh, nil
 }
 
-func (e *Elector) amILeader() (bool, string, error) {
+// amILeader : Returns whether the instance holds the lowest candidate znode, otherwise the znode to follow and the lowest one
+func (e *Elector) amILeader() (bool, string, string, error) {
 	children, err := e.getChildren()
 	if err != nil {
-		return false, "", err
+		return false, "", "", err
 	}
-	sort.Slice(children, func(i, j int) bool {
-		return children[i] < children[j]
-	})
+	sortCandidates(children)
 
 	if e.znode == children[0] {
-		return true, "", nil
+		return true, "", children[0], nil
 	}
 
 	// TODO handle error here what if your node is not found, Can it happen?
@@ -222,9 +256,25 @@ func (e *Elector) amILeader() (bool, string, error) {
 	}
 
 	if i == len(children) {
-		return false, "", errors.New("cannot find the newly created node")
+		return false, "", "", errors.New("cannot find the newly created node")
 	}
-	return false, children[i-1], nil
+	return false, children[i-1], children[0], nil
+}
+
+// watchLowest : Reads the candidate id of the lowest znode and watches it, unless it is the predecessor which is already watched.
+// It returns zk.ErrNoNode if the lowest znode is gone
+func (e *Elector) watchLowest(lowest, toFollow string) (string, <-chan zk.Event, error) {
+	path := strings.Join([]string{e.root, lowest}, sep)
+	if lowest == toFollow {
+		data, _, err := e.conn.Get(path)
+		return string(data), nil, err
+	}
+
+	data, _, ch, err := e.conn.GetW(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(data), ch, nil
 }
 
 func (e *Elector) getChildren() ([]string, error) {
@@ -275,6 +325,13 @@ func (e *Elector) watchPredecessor() {
 				return
 			}
 			return
+		case event := <-e.leaderCh:
+			if event.Type == zk.EventNodeDeleted {
+				e.logger.Info("leader node deleted sending notification to triggerElectionCh")
+				e.triggerElectionCh <- nil
+				return
+			}
+			return
 		case event := <-e.myCh:
 			if event.Type == zk.EventNodeDeleted {
 				err := fmt.Errorf("%s Leader (%v) has been deleted", "watchForLeaderDeleteEvents", e.znode)
@@ -298,3 +355,35 @@ func (e *Elector) resign() {
 	})
 	return
 }
+
+// currentLeader : Reads the candidate id stored in the lowest sequence znode under the election root
+func currentLeader(conn *zk.Conn, root string) (string, error) {
+	children, _, err := conn.Children(root)
+	if err != nil {
+		return "", err
+	}
+	return leaderOf(conn, root, children)
+}
+
+func leaderOf(conn *zk.Conn, root string, children []string) (string, error) {
+	if len(children) == 0 {
+		return "", nil
+	}
+	sortCandidates(children)
+
+	data, _, err := conn.Get(strings.Join([]string{root, children[0]}, sep))
+	if err != nil {
+		if err == zk.ErrNoNode {
+			// leader resigned between listing and reading, the next watch event will catch up
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func sortCandidates(children []string) {
+	sort.Slice(children, func(i, j int) bool {
+		return children[i] < children[j]
+	})
+}
@@ -0,0 +1,144 @@
+package election
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/core/commons"
+)
+
+const retryInterval = time.Second
+
+type (
+	// LeaderChangeFunc : Callback invoked with the candidate id of the new leader, empty if there is no leader
+	LeaderChangeFunc func(leader string)
+
+	// Observer : Watches an election without taking part in it.
+	// It can be used by any process interested in knowing who the leader is without nominating itself
+	Observer struct {
+		root string
+		conn *zk.Conn
+
+		// leaderCh channel for communicating leader changes to the clients
+		leaderCh chan string
+
+		mu        sync.RWMutex
+		callbacks []LeaderChangeFunc
+
+		stopCh chan any // stopCh stops the observe routine
+		once   sync.Once
+		logger commons.Logger
+	}
+)
+
+func NewObserver(conn *zk.Conn, electionRoot string, logger commons.Logger) (*Observer, error) {
+	if conn == nil {
+		return nil, errors.New("conn cannot be nil")
+	}
+
+	exists, _, err := conn.Exists(electionRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("election root %s should be present", electionRoot)
+	}
+
+	return &Observer{
+		root:     electionRoot,
+		conn:     conn,
+		leaderCh: make(chan string, 1),
+		stopCh:   make(chan any),
+		logger:   logger,
+	}, nil
+}
+
+// Observe : Watches the election root and publishes the candidate id of the leader every time it changes.
+// It blocks until Stop is called
+func (o *Observer) Observe() {
+	defer o.once.Do(func() {
+		close(o.leaderCh)
+	})
+
+	last := ""
+	first := true
+	for {
+		leader, ch, err := o.watch()
+		if err != nil {
+			o.logger.Error(err, "observer: error watching election root=%s", o.root)
+			select {
+			case <-time.After(retryInterval):
+				continue
+			case <-o.stopCh:
+				return
+			}
+		}
+
+		if first || leader != last {
+			o.logger.Info("observer: leader changed from=%s, to=%s", last, leader)
+			first = false
+			last = leader
+			o.publish(leader)
+		}
+
+		select {
+		case <-ch:
+		case <-o.stopCh:
+			return
+		}
+	}
+}
+
+// Leaders : Channel on which the candidate id of the leader is published on every change.
+// Only the latest leader is retained if the client is not keeping up
+func (o *Observer) Leaders() chan string {
+	return o.leaderCh
+}
+
+// Leader : Returns the candidate id of the current leader, empty if the election has no candidates
+func (o *Observer) Leader() (string, error) {
+	return currentLeader(o.conn, o.root)
+}
+
+// OnLeaderChange : Registers a callback invoked from the observe routine on every leader change
+func (o *Observer) OnLeaderChange(fn LeaderChangeFunc) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.callbacks = append(o.callbacks, fn)
+}
+
+func (o *Observer) Stop() {
+	close(o.stopCh)
+}
+
+func (o *Observer) watch() (string, <-chan zk.Event, error) {
+	children, _, ch, err := o.conn.ChildrenW(o.root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	leader, err := leaderOf(o.conn, o.root, children)
+	if err != nil {
+		return "", nil, err
+	}
+	return leader, ch, nil
+}
+
+func (o *Observer) publish(leader string) {
+	o.mu.RLock()
+	for _, fn := range o.callbacks {
+		fn(leader)
+	}
+	o.mu.RUnlock()
+
+	// drop the stale value if the client has not consumed it yet
+	select {
+	case <-o.leaderCh:
+	default:
+	}
+	o.leaderCh <- leader
+}
@@ -0,0 +1,486 @@
+// Package enginetest : Conformance tests for api.Engine implementations.
+// An engine package runs the whole suite from its own tests:
+//
+//	func TestEngine(t *testing.T) {
+//		enginetest.Run(t, func(t *testing.T) api.Engine {
+//			return newEmptyEngine(t)
+//		})
+//	}
+package enginetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+// Factory : Returns an engine with no topics and no jobs, it is called once per test
+type Factory func(t *testing.T) api.Engine
+
+// Run : Runs every conformance test against a fresh engine
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, e api.Engine)
+	}{
+		{"GetTopics", testGetTopics},
+		{"RegisterTopic_Duplicate", testRegisterTopicDuplicate},
+		{"Enqueue_UnknownTopic", testEnqueueUnknownTopic},
+		{"Dequeue_Priority", testDequeuePriority},
+		{"Dequeue_Fifo", testDequeueFifo},
+		{"Dequeue_Empty", testDequeueEmpty},
+		{"Dequeue_TopicIsolation", testDequeueTopicIsolation},
+		{"Dequeue_Headers", testDequeueHeaders},
+		{"Dequeue_Concurrent", testDequeueConcurrent},
+		{"GetJob", testGetJob},
+		{"GetJob_NotPresent", testGetJobNotPresent},
+		{"Ack", testAck},
+		{"Ack_WrongConsumer", testAckWrongConsumer},
+		{"Ack_AlreadyAcked", testAckAlreadyAcked},
+		{"Ack_NotPresent", testAckNotPresent},
+		{"Ack_LeaseExceeded", testAckLeaseExceeded},
+		{"ReQueue", testReQueue},
+		{"ReQueue_TopicIsolation", testReQueueTopicIsolation},
+		{"PendingCount", testPendingCount},
+		{"ExtendLease", testExtendLease},
+		{"ExtendLease_Errors", testExtendLeaseErrors},
+		{"Nack", testNack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory(t))
+		})
+	}
+}
+
+func testGetTopics(t *testing.T, e api.Engine) {
+	ctx := context.Background()
+	registerTopics(t, e, "t1", "t2")
+
+	topics, err := e.GetTopics(ctx)
+	if err != nil {
+		t.Fatalf("GetTopics() err=%v", err)
+	}
+	sort.Strings(topics)
+	if fmt.Sprint(topics) != fmt.Sprint([]string{"t1", "t2"}) {
+		t.Errorf("GetTopics() got = %q, want %q", topics, []string{"t1", "t2"})
+	}
+}
+
+func testRegisterTopicDuplicate(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	if _, err := e.RegisterTopic(context.Background(), api.RegisterTopicRequest{Name: "t1"}); err == nil {
+		t.Errorf("RegisterTopic() twice should fail")
+	}
+}
+
+func testEnqueueUnknownTopic(t *testing.T, e api.Engine) {
+	if _, err := e.Enqueue(context.Background(), api.EnqueueRequest{Topic: "unknown", Priority: 1}); err == nil {
+		t.Errorf("Enqueue() on an unregistered topic should fail")
+	}
+}
+
+func testDequeuePriority(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	ids := make(map[int32]int64)
+	for _, p := range []int32{1, 10, -5, 3} {
+		ids[p] = enqueue(t, e, "t1", p)
+	}
+
+	for _, p := range []int32{10, 3, 1, -5} {
+		res := dequeue(t, e, "t1", "c1")
+		if res.JobId != ids[p] || res.Priority != p {
+			t.Errorf("Dequeue() got job=%d priority=%d, want job=%d priority=%d", res.JobId, res.Priority, ids[p], p)
+		}
+	}
+}
+
+func testDequeueFifo(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	ids := make([]int64, 5)
+	for i := range ids {
+		ids[i] = enqueue(t, e, "t1", 7)
+	}
+
+	for _, id := range ids {
+		if res := dequeue(t, e, "t1", "c1"); res.JobId != id {
+			t.Errorf("Dequeue() got job=%d, want job=%d", res.JobId, id)
+		}
+	}
+}
+
+func testDequeueEmpty(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	if res := dequeue(t, e, "t1", "c1"); res.JobId != 0 {
+		t.Errorf("Dequeue() on an empty topic got job=%d, want none", res.JobId)
+	}
+
+	enqueue(t, e, "t1", 1)
+	dequeue(t, e, "t1", "c1")
+	if res := dequeue(t, e, "t1", "c1"); res.JobId != 0 {
+		t.Errorf("Dequeue() on a drained topic got job=%d, want none", res.JobId)
+	}
+}
+
+func testDequeueTopicIsolation(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1", "t2")
+	id := enqueue(t, e, "t2", 1)
+
+	if res := dequeue(t, e, "t1", "c1"); res.JobId != 0 {
+		t.Errorf("Dequeue() got job=%d of another topic", res.JobId)
+	}
+
+	res := dequeue(t, e, "t2", "c1")
+	if res.JobId != id || res.Topic != "t2" || string(res.Payload) != "payload" {
+		t.Errorf("Dequeue() got = %+v, want job=%d topic=t2 payload=payload", res, id)
+	}
+}
+
+func testDequeueHeaders(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	headers := map[string]string{"content-type": "application/json", "traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+	if _, err := e.Enqueue(context.Background(), api.EnqueueRequest{Topic: "t1", Priority: 1, Headers: headers}); err != nil {
+		t.Fatalf("Enqueue() err=%v", err)
+	}
+	enqueue(t, e, "t1", 0)
+
+	if res := dequeue(t, e, "t1", "c1"); fmt.Sprint(res.Headers) != fmt.Sprint(headers) {
+		t.Errorf("Dequeue() got headers = %v, want %v", res.Headers, headers)
+	}
+
+	if res := dequeue(t, e, "t1", "c1"); len(res.Headers) != 0 {
+		t.Errorf("Dequeue() got headers = %v for a job without headers", res.Headers)
+	}
+}
+
+// testDequeueConcurrent : Every job is claimed exactly once by consumers racing on the topic
+func testDequeueConcurrent(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	const jobs, consumers = 200, 10
+	for i := 0; i < jobs; i++ {
+		enqueue(t, e, "t1", int32(i%7))
+	}
+
+	var mu sync.Mutex
+	claimed := make(map[int64]string)
+	var wg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func(consumer string) {
+			defer wg.Done()
+			for {
+				res, err := e.Dequeue(context.Background(), api.DequeueRequest{Topic: "t1", Consumer: consumer})
+				if err != nil {
+					t.Errorf("Dequeue() err=%v", err)
+					return
+				}
+				if res.JobId == 0 {
+					return
+				}
+				mu.Lock()
+				if other, ok := claimed[res.JobId]; ok {
+					t.Errorf("job=%d claimed by %s and %s", res.JobId, other, consumer)
+				}
+				claimed[res.JobId] = consumer
+				mu.Unlock()
+			}
+		}(strconv.Itoa(c))
+	}
+	wg.Wait()
+
+	if len(claimed) != jobs {
+		t.Errorf("claimed %d jobs, want %d", len(claimed), jobs)
+	}
+}
+
+func testGetJob(t *testing.T, e api.Engine) {
+	ctx := context.Background()
+	registerTopics(t, e, "t1")
+	headers := map[string]string{"content-type": "application/json", "tenant": "acme"}
+	enq, err := e.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 3, Payload: []byte("payload"), Headers: headers})
+	if err != nil {
+		t.Fatalf("Enqueue() err=%v", err)
+	}
+
+	job := getJob(t, e, enq.JobId)
+	if job.JobId != enq.JobId || job.Topic != "t1" || job.Priority != 3 || string(job.Payload) != "payload" {
+		t.Errorf("GetJob() got = %+v, want job=%d topic=t1 priority=3 payload=payload", job, enq.JobId)
+	}
+	if fmt.Sprint(job.Headers) != fmt.Sprint(headers) {
+		t.Errorf("GetJob() got headers = %v, want %v", job.Headers, headers)
+	}
+	if job.Status != api.JobPending {
+		t.Errorf("GetJob() got status = %v, want %v", job.Status, api.JobPending)
+	}
+
+	dequeue(t, e, "t1", "c1")
+	if job = getJob(t, e, enq.JobId); job.Status != api.JobClaimed || job.ClaimedBy != "c1" {
+		t.Errorf("GetJob() after dequeue got status = %v claimed_by=%q, want %v claimed_by=c1", job.Status, job.ClaimedBy, api.JobClaimed)
+	}
+
+	if _, err = e.Ack(ctx, api.AckRequest{JobId: enq.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Ack() err=%v", err)
+	}
+	if job = getJob(t, e, enq.JobId); job.Status != api.JobCompleted {
+		t.Errorf("GetJob() after ack got status = %v, want %v", job.Status, api.JobCompleted)
+	}
+}
+
+func testGetJobNotPresent(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	id := enqueue(t, e, "t1", 1)
+	if _, err := e.GetJob(context.Background(), api.GetJobRequest{JobId: id + 1000}); !errors.Is(err, api.ErrorJobNotPresent) {
+		t.Errorf("GetJob() err=%v, want %v", err, api.ErrorJobNotPresent)
+	}
+}
+
+func testAck(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	ack, err := e.Ack(context.Background(), api.AckRequest{JobId: res.JobId, Consumer: "c1"})
+	if err != nil || !ack.Acked {
+		t.Errorf("Ack() acked=%v, err=%v", ack.Acked, err)
+	}
+}
+
+func testAckWrongConsumer(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	assertAckError(t, e, res.JobId, "c2", api.ErrorWrongConsumer)
+
+	// the rightful consumer can still ack
+	if _, err := e.Ack(context.Background(), api.AckRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Errorf("Ack() err=%v", err)
+	}
+}
+
+func testAckAlreadyAcked(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	if _, err := e.Ack(context.Background(), api.AckRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Ack() err=%v", err)
+	}
+	assertAckError(t, e, res.JobId, "c1", api.ErrorAlreadyAcked)
+}
+
+func testAckNotPresent(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	id := enqueue(t, e, "t1", 1)
+	assertAckError(t, e, id+1000, "c1", api.ErrorJobNotPresent)
+}
+
+func testAckLeaseExceeded(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+	reQueue(t, e, "t1", time.Now().Add(time.Second))
+
+	assertAckError(t, e, res.JobId, "c1", api.ErrorLeaseExceeded)
+}
+
+func testReQueue(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	if count := reQueue(t, e, "t1", time.Now().Add(-time.Hour)); count != 0 {
+		t.Errorf("ReQueue() before the claim got count=%d, want 0", count)
+	}
+
+	if count := reQueue(t, e, "t1", time.Now().Add(time.Second)); count != 1 {
+		t.Errorf("ReQueue() after the claim got count=%d, want 1", count)
+	}
+
+	if count := reQueue(t, e, "t1", time.Now().Add(time.Second)); count != 0 {
+		t.Errorf("ReQueue() twice got count=%d, want 0", count)
+	}
+
+	if again := dequeue(t, e, "t1", "c2"); again.JobId != res.JobId {
+		t.Errorf("Dequeue() after requeue got job=%d, want job=%d", again.JobId, res.JobId)
+	}
+}
+
+func testReQueueTopicIsolation(t *testing.T, e api.Engine) {
+	registerTopics(t, e, "t1", "t2")
+	enqueue(t, e, "t1", 1)
+	enqueue(t, e, "t2", 1)
+	dequeue(t, e, "t1", "c1")
+	dequeue(t, e, "t2", "c1")
+
+	if count := reQueue(t, e, "t1", time.Now().Add(time.Second)); count != 1 {
+		t.Errorf("ReQueue() got count=%d, want 1", count)
+	}
+
+	if res := dequeue(t, e, "t2", "c2"); res.JobId != 0 {
+		t.Errorf("ReQueue() re-queued job=%d of another topic", res.JobId)
+	}
+}
+
+// testPendingCount : Only runs for the engines implementing api.Inspector
+func testPendingCount(t *testing.T, e api.Engine) {
+	inspector, ok := e.(api.Inspector)
+	if !ok {
+		t.Skip("engine does not implement api.Inspector")
+	}
+
+	registerTopics(t, e, "t1", "t2")
+	for i := 0; i < 3; i++ {
+		enqueue(t, e, "t1", int32(i))
+	}
+	enqueue(t, e, "t2", 1)
+	dequeue(t, e, "t1", "c1")
+
+	count, err := inspector.PendingCount(context.Background(), "t1")
+	if err != nil || count != 2 {
+		t.Errorf("PendingCount() got = %d, err=%v, want 2", count, err)
+	}
+}
+
+// testExtendLease : Only runs for the engines implementing api.Leaser
+func testExtendLease(t *testing.T, e api.Engine) {
+	leaser := asLeaser(t, e)
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	time.Sleep(10 * time.Millisecond)
+	extendedAfter := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	if _, err := leaser.ExtendLease(context.Background(), api.LeaseRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("ExtendLease() err=%v", err)
+	}
+	if count := reQueue(t, e, "t1", extendedAfter); count != 0 {
+		t.Errorf("ReQueue() of an extended lease got count=%d, want 0", count)
+	}
+	if _, err := e.Ack(context.Background(), api.AckRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Errorf("Ack() after ExtendLease() err=%v", err)
+	}
+}
+
+func testExtendLeaseErrors(t *testing.T, e api.Engine) {
+	leaser := asLeaser(t, e)
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	tests := []struct {
+		name string
+		req  api.LeaseRequest
+		want error
+	}{
+		{name: "Wrong consumer", req: api.LeaseRequest{JobId: res.JobId, Consumer: "c2"}, want: api.ErrorWrongConsumer},
+		{name: "Not present", req: api.LeaseRequest{JobId: res.JobId + 1000, Consumer: "c1"}, want: api.ErrorJobNotPresent},
+	}
+	for _, tt := range tests {
+		if _, err := leaser.ExtendLease(context.Background(), tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ExtendLease() err=%v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := leaser.Nack(context.Background(), tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Nack() err=%v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	reQueue(t, e, "t1", time.Now().Add(time.Second))
+	if _, err := leaser.ExtendLease(context.Background(), api.LeaseRequest{JobId: res.JobId, Consumer: "c1"}); !errors.Is(err, api.ErrorLeaseExceeded) {
+		t.Errorf("ExtendLease() of a re-queued job err=%v, want %v", err, api.ErrorLeaseExceeded)
+	}
+}
+
+func testNack(t *testing.T, e api.Engine) {
+	leaser := asLeaser(t, e)
+	registerTopics(t, e, "t1")
+	enqueue(t, e, "t1", 1)
+	res := dequeue(t, e, "t1", "c1")
+
+	if _, err := leaser.Nack(context.Background(), api.LeaseRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Nack() err=%v", err)
+	}
+	if job := getJob(t, e, res.JobId); job.Status != api.JobPending {
+		t.Errorf("GetJob() after nack got status = %v, want %v", job.Status, api.JobPending)
+	}
+	assertAckError(t, e, res.JobId, "c1", api.ErrorLeaseExceeded)
+
+	if again := dequeue(t, e, "t1", "c2"); again.JobId != res.JobId {
+		t.Errorf("Dequeue() after nack got job=%d, want job=%d", again.JobId, res.JobId)
+	}
+}
+
+func asLeaser(t *testing.T, e api.Engine) api.Leaser {
+	t.Helper()
+	leaser, ok := e.(api.Leaser)
+	if !ok {
+		t.Skip("engine does not implement api.Leaser")
+	}
+	return leaser
+}
+
+func registerTopics(t *testing.T, e api.Engine, topics ...string) {
+	t.Helper()
+	for _, topic := range topics {
+		if _, err := e.RegisterTopic(context.Background(), api.RegisterTopicRequest{Name: topic}); err != nil {
+			t.Fatalf("RegisterTopic() topic=%s err=%v", topic, err)
+		}
+	}
+}
+
+func enqueue(t *testing.T, e api.Engine, topic string, priority int32) int64 {
+	t.Helper()
+	res, err := e.Enqueue(context.Background(), api.EnqueueRequest{Topic: topic, Priority: priority, Payload: []byte("payload")})
+	if err != nil {
+		t.Fatalf("Enqueue() err=%v", err)
+	}
+	return res.JobId
+}
+
+func dequeue(t *testing.T, e api.Engine, topic, consumer string) api.DequeueResponse {
+	t.Helper()
+	res, err := e.Dequeue(context.Background(), api.DequeueRequest{Topic: topic, Consumer: consumer})
+	if err != nil {
+		t.Fatalf("Dequeue() err=%v", err)
+	}
+	return res
+}
+
+func getJob(t *testing.T, e api.Engine, id int64) api.GetJobResponse {
+	t.Helper()
+	res, err := e.GetJob(context.Background(), api.GetJobRequest{JobId: id})
+	if err != nil {
+		t.Fatalf("GetJob() err=%v", err)
+	}
+	return res
+}
+
+func reQueue(t *testing.T, e api.Engine, topic string, ts time.Time) int64 {
+	t.Helper()
+	res, err := e.ReQueue(context.Background(), api.RequeueRequest{Topic: topic, RequeueTs: ts.UnixMilli()})
+	if err != nil {
+		t.Fatalf("ReQueue() err=%v", err)
+	}
+	return res.Count
+}
+
+func assertAckError(t *testing.T, e api.Engine, jobId int64, consumer string, want error) {
+	t.Helper()
+	ack, err := e.Ack(context.Background(), api.AckRequest{JobId: jobId, Consumer: consumer})
+	if !errors.Is(err, want) {
+		t.Errorf("Ack() err=%v, want %v", err, want)
+	}
+	if ack.Acked {
+		t.Errorf("Ack() acked=true on error %v", want)
+	}
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"context"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+// ExtendLease : Extends the lease of a claimed job, it returns api.ErrorNotSupported if the engine is not an api.Leaser
+func (w *Worker) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := w.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	return l.ExtendLease(ctx, req)
+}
+
+// Nack : Moves a claimed job back to pending, it returns api.ErrorNotSupported if the engine is not an api.Leaser.
+// The waiting dequeue calls pick the job up on their next poll
+func (w *Worker) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := w.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	return l.Nack(ctx, req)
+}
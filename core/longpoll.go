@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultMaxWait      = 30 * time.Second
+)
+
+type (
+	// LongPolling : How the dequeue calls with a WaitTimeout wait for a job
+	LongPolling struct {
+		PollInterval time.Duration // PollInterval: the engine is re-checked at this interval for jobs enqueued through other workers, defaults to 1s
+		MaxWait      time.Duration // MaxWait: upper bound of the WaitTimeout of a request, defaults to 30s
+	}
+
+	// waiters : Wakes the dequeue calls waiting on a topic when a job is enqueued through this worker
+	waiters struct {
+		mu     sync.Mutex
+		topics map[string]chan struct{}
+	}
+)
+
+// WithLongPolling : Configures the dequeue calls waiting for a job, zero values keep the defaults
+func WithLongPolling(lp LongPolling) Option {
+	return func(w *Worker) {
+		if lp.PollInterval > 0 {
+			w.longPolling.PollInterval = lp.PollInterval
+		}
+		if lp.MaxWait > 0 {
+			w.longPolling.MaxWait = lp.MaxWait
+		}
+	}
+}
+
+func newWaiters() *waiters {
+	return &waiters{topics: make(map[string]chan struct{})}
+}
+
+// wait : Returns a channel closed on the next enqueue on the topic
+func (ws *waiters) wait(topic string) <-chan struct{} {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ch, ok := ws.topics[topic]
+	if !ok {
+		ch = make(chan struct{})
+		ws.topics[topic] = ch
+	}
+	return ch
+}
+
+// notify : Wakes all the calls waiting on the topic
+func (ws *waiters) notify(topic string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ch, ok := ws.topics[topic]; ok {
+		close(ch)
+		delete(ws.topics, topic)
+	}
+}
+
+// Enqueue : Enqueues the job and wakes the dequeue calls waiting on its topic
+func (w *Worker) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	res, err := w.Engine.Enqueue(ctx, req)
+	if err == nil {
+		w.waiters.notify(req.Topic)
+	}
+	return res, err
+}
+
+// Dequeue : Dequeues a job, with a WaitTimeout an empty topic is retried when a job is enqueued through this worker,
+// when an api.Notifier engine reports a job enqueued through any worker or every PollInterval, until a job is found
+// or the timeout elapses. It returns empty on timeout
+func (w *Worker) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	if req.WaitTimeout <= 0 {
+		return w.Engine.Dequeue(ctx, req)
+	}
+
+	wait := req.WaitTimeout
+	if wait > w.longPolling.MaxWait {
+		wait = w.longPolling.MaxWait
+	}
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	poll := time.NewTicker(w.longPolling.PollInterval)
+	defer poll.Stop()
+
+	for {
+		res, done, err := w.wait(ctx, req, poll.C, timeout.C)
+		if err != nil || done {
+			return res, err
+		}
+	}
+}
+
+// wait : Dequeues once and waits for a wake up if the topic is empty, done is true if the call is over
+func (w *Worker) wait(ctx context.Context, req api.DequeueRequest, poll <-chan time.Time, timeout <-chan time.Time) (api.DequeueResponse, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// registered before the dequeue so that an enqueue in between is not missed
+	wake := w.waiters.wait(req.Topic)
+	remote := w.engineWake(ctx, req.Topic)
+	res, err := w.Engine.Dequeue(ctx, req)
+	if err != nil || res.JobId != 0 {
+		return res, true, err
+	}
+
+	select {
+	case <-wake:
+	case <-remote:
+	case <-poll:
+	case <-timeout:
+		return api.DequeueResponse{}, true, nil
+	case <-ctx.Done():
+		return api.DequeueResponse{}, true, ctx.Err()
+	}
+	return api.DequeueResponse{}, false, nil
+}
+
+// engineWake : Returns a channel closed when the engine reports a job enqueued on the topic by any worker,
+// it is never closed if the engine is not an api.Notifier
+func (w *Worker) engineWake(ctx context.Context, topic string) <-chan struct{} {
+	ch := make(chan struct{})
+	n, ok := w.Engine.(api.Notifier)
+	if !ok {
+		return ch
+	}
+	go func() {
+		if n.WaitForJob(ctx, topic) == nil {
+			close(ch)
+		}
+	}()
+	return ch
+}
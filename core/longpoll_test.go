@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+// queueEngine : Fifo queue per topic, only implements Enqueue and Dequeue
+type queueEngine struct {
+	api.Engine
+	mu     sync.Mutex
+	lastId int64
+	topics map[string][]int64
+}
+
+func (q *queueEngine) Enqueue(_ context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastId++
+	q.topics[req.Topic] = append(q.topics[req.Topic], q.lastId)
+	return api.EnqueueResponse{JobId: q.lastId}, nil
+}
+
+func (q *queueEngine) Dequeue(_ context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := q.topics[req.Topic]
+	if len(ids) == 0 {
+		return api.DequeueResponse{}, nil
+	}
+	q.topics[req.Topic] = ids[1:]
+	return api.DequeueResponse{JobId: ids[0], Topic: req.Topic}, nil
+}
+
+// notifyingEngine : Signals the enqueues on the queue to the waiting workers like a shared database would
+type notifyingEngine struct {
+	*queueEngine
+	enqueued chan string
+}
+
+func (n *notifyingEngine) WaitForJob(ctx context.Context, topic string) error {
+	for {
+		select {
+		case t := <-n.enqueued:
+			if t == topic {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestWorker_Dequeue_Wait(t *testing.T) {
+	tests := []struct {
+		name        string
+		longPolling LongPolling
+		wait        time.Duration
+		enqueue     func(w *Worker, e *queueEngine)
+		wantJob     bool
+		maxElapsed  time.Duration
+	}{
+		{
+			name:        "Woken by an enqueue on the worker",
+			longPolling: LongPolling{PollInterval: time.Hour},
+			wait:        5 * time.Second,
+			enqueue: func(w *Worker, _ *queueEngine) {
+				_, _ = w.Enqueue(context.Background(), api.EnqueueRequest{Topic: "t1"})
+			},
+			wantJob:    true,
+			maxElapsed: time.Second,
+		},
+		{
+			name:        "Enqueue on another worker found by polling",
+			longPolling: LongPolling{PollInterval: 20 * time.Millisecond},
+			wait:        5 * time.Second,
+			enqueue: func(_ *Worker, e *queueEngine) {
+				_, _ = e.Enqueue(context.Background(), api.EnqueueRequest{Topic: "t1"})
+			},
+			wantJob:    true,
+			maxElapsed: time.Second,
+		},
+		{
+			name:        "Enqueue on another topic",
+			longPolling: LongPolling{PollInterval: 20 * time.Millisecond},
+			wait:        200 * time.Millisecond,
+			enqueue: func(w *Worker, _ *queueEngine) {
+				_, _ = w.Enqueue(context.Background(), api.EnqueueRequest{Topic: "t2"})
+			},
+			wantJob:    false,
+			maxElapsed: time.Second,
+		},
+		{
+			name:        "Wait capped by MaxWait",
+			longPolling: LongPolling{MaxWait: 100 * time.Millisecond},
+			wait:        time.Hour,
+			enqueue:     func(*Worker, *queueEngine) {},
+			wantJob:     false,
+			maxElapsed:  time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &queueEngine{topics: make(map[string][]int64)}
+			w := NewWorker(nil, e, WithLongPolling(tt.longPolling))
+
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				tt.enqueue(w, e)
+			}()
+
+			start := time.Now()
+			res, err := w.Dequeue(context.Background(), api.DequeueRequest{Topic: "t1", Consumer: "c1", WaitTimeout: tt.wait})
+			if err != nil {
+				t.Fatalf("Dequeue() err=%v", err)
+			}
+			if got := res.JobId != 0; got != tt.wantJob {
+				t.Errorf("Dequeue() got job = %v, want %v", got, tt.wantJob)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxElapsed {
+				t.Errorf("Dequeue() returned after %s, want less than %s", elapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestWorker_Dequeue_Cancel(t *testing.T) {
+	w := NewWorker(nil, &queueEngine{topics: make(map[string][]int64)})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1", WaitTimeout: time.Second}); err != context.DeadlineExceeded {
+		t.Errorf("Dequeue() err=%v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWorker_Dequeue_Notifier(t *testing.T) {
+	e := &notifyingEngine{queueEngine: &queueEngine{topics: make(map[string][]int64)}, enqueued: make(chan string)}
+	w := NewWorker(nil, e, WithLongPolling(LongPolling{PollInterval: time.Hour}))
+
+	// an enqueue through another worker sharing the engine
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = e.Enqueue(context.Background(), api.EnqueueRequest{Topic: "t1"})
+		e.enqueued <- "t1"
+	}()
+
+	start := time.Now()
+	res, err := w.Dequeue(context.Background(), api.DequeueRequest{Topic: "t1", Consumer: "c1", WaitTimeout: 5 * time.Second})
+	if err != nil || res.JobId == 0 {
+		t.Fatalf("Dequeue() got = %v, err=%v, want the job", res, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dequeue() returned after %s, want a wake up by the engine", elapsed)
+	}
+}
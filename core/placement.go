@@ -0,0 +1,255 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/core/api"
+)
+
+const (
+	placementRoot = "/%s/placements"
+	placementNode = "/%s/placements/%s"
+)
+
+type (
+	// Metadata : Information advertised by a worker in its membership znode
+	Metadata struct {
+		Addr     string            `json:"addr,omitempty"`     // Addr: address on which the worker serves its api
+		Version  string            `json:"version,omitempty"`  // Version: version of the worker binary
+		Capacity int               `json:"capacity,omitempty"` // Capacity: free form capacity hint
+		Labels   map[string]string `json:"labels,omitempty"`   // Labels: labels matched against the topic placement constraints
+	}
+
+	// Member : A worker registered in the namespace along with its metadata
+	Member struct {
+		ID string
+		Metadata
+	}
+
+	// placement : Constraints stored for a topic, a topic is only assigned to the workers matching all the labels
+	placement struct {
+		Labels map[string]string `json:"labels"`
+	}
+
+	// assignmentRules : Everything apart from the membership the leader takes into account while partitioning
+	assignmentRules struct {
+		placements map[string]map[string]string // placements: topic -> labels required on the worker
+		pins       map[string]string            // pins: topic -> worker the topic is pinned to
+		cordons    map[string]bool              // cordons: workers excluded from the assignment
+	}
+)
+
+// Matches : Reports whether the member labels satisfy the placement labels.
+// An empty value in the placement only requires the label key to be present
+func (m Metadata) Matches(labels map[string]string) bool {
+	for k, v := range labels {
+		mv, ok := m.Labels[k]
+		if !ok || (v != "" && mv != v) {
+			return false
+		}
+	}
+	return true
+}
+
+func WithMetadata(meta Metadata) Option {
+	return func(w *Worker) {
+		w.meta = meta
+	}
+}
+
+// RegisterTopic : Registers the topic with the engine and stores its placement constraints in zookeeper,
+// a topic registered without a placement drops any placement left over from an earlier registration
+func (w *Worker) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	res, err := w.Engine.RegisterTopic(ctx, req)
+	if err != nil {
+		return res, err
+	}
+	return res, w.setPlacement(req.Name, req.Placement)
+}
+
+// setPlacement : Replaces the placement of the topic, an empty placement deletes it.
+// Placements are re-created instead of updated so that the leader's children watch fires
+func (w *Worker) setPlacement(topic string, labels map[string]string) error {
+	// before Start there is no zookeeper session and so no placement to clear
+	if w.conn == nil && len(labels) == 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf(placementNode, w.Namespace, topic)
+	err := w.conn.Delete(path, -1)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(placement{Labels: labels})
+	if err != nil {
+		return err
+	}
+	_, err = w.conn.Create(path, data, 0, w.acl)
+	return err
+}
+
+// Members : Returns all the workers registered in the namespace along with their metadata
+func (w *Worker) Members() ([]Member, error) {
+	children, _, err := w.conn.Children(fmt.Sprintf(membershipRoot, w.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	return w.members(children)
+}
+
+func (w *Worker) members(children []string) ([]Member, error) {
+	members := make([]Member, 0, len(children))
+	for _, child := range children {
+		data, _, err := w.conn.Get(fmt.Sprintf(memberNode, w.Namespace, child))
+		if err == zk.ErrNoNode {
+			// member left after listing, next membership event will re-balance
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m := Member{ID: child}
+		if len(data) > 0 {
+			if err = json.Unmarshal(data, &m.Metadata); err != nil {
+				w.logger.Error(err, "invalid metadata for member=%s", child)
+			}
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (w *Worker) placements() (map[string]map[string]string, error) {
+	root := fmt.Sprintf(placementRoot, w.Namespace)
+	topics, _, err := w.conn.Children(root)
+	if err != nil {
+		return nil, err
+	}
+
+	placements := make(map[string]map[string]string)
+	for _, topic := range topics {
+		data, _, err := w.conn.Get(fmt.Sprintf(placementNode, w.Namespace, topic))
+		if err == zk.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var p placement
+		if err = json.Unmarshal(data, &p); err != nil {
+			w.logger.Error(err, "invalid placement for topic=%s", topic)
+			continue
+		}
+		placements[topic] = p.Labels
+	}
+	return placements, nil
+}
+
+func (w *Worker) assignmentRules() (assignmentRules, error) {
+	placements, err := w.placements()
+	if err != nil {
+		return assignmentRules{}, err
+	}
+
+	admin := w.Admin()
+	pins, err := admin.Pins()
+	if err != nil {
+		return assignmentRules{}, err
+	}
+
+	cordons, err := admin.Cordons()
+	if err != nil {
+		return assignmentRules{}, err
+	}
+	return assignmentRules{placements: placements, pins: pins, cordons: cordons}, nil
+}
+
+// calculatePlacement : Assigns the pinned topics to their workers, distributes the unconstrained topics evenly
+// among the remaining workers and assigns every constrained topic to the least loaded worker matching its labels.
+// Cordoned workers receive no topics and workers with pinned topics are dedicated to them.
+// A pin to an unavailable worker is ignored, constrained topics without any matching worker are left unassigned
+func calculatePlacement(topics []string, members []Member, rules assignmentRules) (membershipData, []string) {
+	available := make(map[string]bool)
+	for _, m := range members {
+		if !rules.cordons[m.ID] {
+			available[m.ID] = true
+		}
+	}
+
+	pinned := make(map[string]map[string]bool)
+	rest := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if target, ok := rules.pins[topic]; ok && available[target] {
+			if _, ok := pinned[target]; !ok {
+				pinned[target] = make(map[string]bool)
+			}
+			pinned[target][topic] = true
+			continue
+		}
+		rest = append(rest, topic)
+	}
+
+	eligible := make([]Member, 0, len(members))
+	for _, m := range members {
+		if available[m.ID] && pinned[m.ID] == nil {
+			eligible = append(eligible, m)
+		}
+	}
+
+	free := make([]string, 0, len(rest))
+	constrained := make([]string, 0)
+	for _, topic := range rest {
+		if _, ok := rules.placements[topic]; ok {
+			constrained = append(constrained, topic)
+		} else {
+			free = append(free, topic)
+		}
+	}
+
+	children := make([]string, len(eligible))
+	for i, m := range eligible {
+		children[i] = m.ID
+	}
+	partition := calculatePartition(free, children)
+
+	unassigned := make([]string, 0)
+	if len(children) == 0 {
+		unassigned = append(unassigned, free...)
+	}
+
+	for _, topic := range constrained {
+		target := ""
+		for _, m := range eligible {
+			if !m.Matches(rules.placements[topic]) {
+				continue
+			}
+			if target == "" || len(partition[m.ID]) < len(partition[target]) {
+				target = m.ID
+			}
+		}
+
+		if target == "" {
+			unassigned = append(unassigned, topic)
+			continue
+		}
+		partition[target][topic] = true
+	}
+
+	for _, m := range members {
+		if assigned, ok := pinned[m.ID]; ok {
+			partition[m.ID] = assigned
+		} else if _, ok := partition[m.ID]; !ok {
+			partition[m.ID] = make(map[string]bool)
+		}
+	}
+	return partition, unassigned
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_calculatePlacement(t *testing.T) {
+	members := []Member{
+		{ID: "c1", Metadata: Metadata{Labels: map[string]string{"region": "eu"}}},
+		{ID: "c2", Metadata: Metadata{Labels: map[string]string{"region": "us", "gpu-free": ""}}},
+		{ID: "c3", Metadata: Metadata{Labels: map[string]string{"region": "us"}}},
+	}
+	type args struct {
+		topics []string
+		rules  assignmentRules
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       membershipData
+		unassigned []string
+	}{
+		{
+			name: "No Constraints",
+			args: args{
+				topics: []string{"t1", "t2", "t3"},
+			},
+			want: map[string]map[string]bool{
+				"c1": {"t1": true},
+				"c2": {"t2": true},
+				"c3": {"t3": true},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "Label Value",
+			args: args{
+				topics: []string{"t1", "t2", "t3", "t4"},
+				rules:  assignmentRules{placements: map[string]map[string]string{"t3": {"region": "eu"}, "t4": {"region": "eu"}}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {"t1": true, "t3": true, "t4": true},
+				"c2": {"t2": true},
+				"c3": {},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "Least Loaded Match",
+			args: args{
+				topics: []string{"t1", "t2", "t3", "t4"},
+				rules:  assignmentRules{placements: map[string]map[string]string{"t3": {"region": "us"}, "t4": {"region": "us"}}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {"t1": true},
+				"c2": {"t2": true, "t4": true},
+				"c3": {"t3": true},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "Label Key",
+			args: args{
+				topics: []string{"t1"},
+				rules:  assignmentRules{placements: map[string]map[string]string{"t1": {"gpu-free": ""}}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {},
+				"c2": {"t1": true},
+				"c3": {},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "No Match",
+			args: args{
+				topics: []string{"t1", "t2"},
+				rules:  assignmentRules{placements: map[string]map[string]string{"t2": {"region": "ap"}}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {},
+				"c2": {},
+				"c3": {"t1": true},
+			},
+			unassigned: []string{"t2"},
+		},
+		{
+			name: "Pinned Topic",
+			args: args{
+				topics: []string{"t1", "t2", "t3", "t4"},
+				rules:  assignmentRules{pins: map[string]string{"t4": "c2"}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {"t1": true, "t2": true},
+				"c2": {"t4": true},
+				"c3": {"t3": true},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "Cordoned Worker",
+			args: args{
+				topics: []string{"t1", "t2", "t3", "t4"},
+				rules:  assignmentRules{cordons: map[string]bool{"c1": true}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {},
+				"c2": {"t1": true, "t2": true},
+				"c3": {"t3": true, "t4": true},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "Pinned To Cordoned Worker",
+			args: args{
+				topics: []string{"t1", "t2"},
+				rules: assignmentRules{
+					pins:    map[string]string{"t1": "c1"},
+					cordons: map[string]bool{"c1": true, "c3": true},
+				},
+			},
+			want: map[string]map[string]bool{
+				"c1": {},
+				"c2": {"t1": true, "t2": true},
+				"c3": {},
+			},
+			unassigned: []string{},
+		},
+		{
+			name: "All Cordoned",
+			args: args{
+				topics: []string{"t1"},
+				rules:  assignmentRules{cordons: map[string]bool{"c1": true, "c2": true, "c3": true}},
+			},
+			want: map[string]map[string]bool{
+				"c1": {},
+				"c2": {},
+				"c3": {},
+			},
+			unassigned: []string{"t1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unassigned := calculatePlacement(tt.args.topics, members, tt.args.rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calculatePlacement() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(unassigned, tt.unassigned) {
+				t.Errorf("calculatePlacement() unassigned = %v, want %v", unassigned, tt.unassigned)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+const defaultPurgeBatchSize = 1000
+
+type (
+	// Retention : How long completed jobs of a topic are kept and what happens to them afterwards
+	Retention struct {
+		After   time.Duration // After: completed jobs older than this are purged, zero keeps them forever
+		Archive bool          // Archive: move purged jobs to the archive instead of deleting them
+	}
+
+	// RetentionPolicy : Default retention with per topic overrides
+	RetentionPolicy struct {
+		Default   Retention
+		Topics    map[string]Retention
+		BatchSize int // BatchSize: max jobs purged per topic on every maintenance run
+	}
+)
+
+// WithRetention : Purges completed jobs of the owned topics during maintenance, the engine must implement api.Purger
+func WithRetention(policy RetentionPolicy) Option {
+	return func(w *Worker) {
+		if policy.BatchSize <= 0 {
+			policy.BatchSize = defaultPurgeBatchSize
+		}
+		w.retention = &policy
+	}
+}
+
+func (p *RetentionPolicy) forTopic(topic string) Retention {
+	if r, ok := p.Topics[topic]; ok {
+		return r
+	}
+	return p.Default
+}
+
+// purge : Purges one batch of completed jobs of the topic, it is a no-op without a retention or an api.Purger engine.
+// An engine behind middlewares is always a Purger, the ErrorNotSupported of an engine which is not is only a warning
+func (w *Worker) purge(ctx context.Context, topic string) {
+	if w.retention == nil {
+		return
+	}
+
+	purger, ok := w.Engine.(api.Purger)
+	if !ok {
+		return
+	}
+
+	r := w.retention.forTopic(topic)
+	if r.After <= 0 {
+		return
+	}
+
+	res, err := purger.Purge(ctx, api.PurgeRequest{
+		Topic:           topic,
+		CompletedBefore: time.Now().Add(-r.After).UnixMilli(),
+		Archive:         r.Archive,
+		BatchSize:       w.retention.BatchSize,
+	})
+	if errors.Is(err, api.ErrorNotSupported) {
+		w.logger.Warn("purge not supported by the engine, topic=%s, archive=%v", topic, r.Archive)
+		return
+	}
+	if err != nil {
+		w.logger.Error(err, "failed to purge jobs for topic %s", topic)
+		return
+	}
+
+	if res.Count > 0 {
+		w.logger.Info("purged jobs=%d, topic=%s, archive=%v", res.Count, topic, r.Archive)
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+type purgerEngine struct {
+	api.Engine
+	requests []api.PurgeRequest
+}
+
+func (p *purgerEngine) Purge(_ context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	p.requests = append(p.requests, req)
+	return api.PurgeResponse{}, nil
+}
+
+func TestWorker_purge(t *testing.T) {
+	policy := RetentionPolicy{
+		Default: Retention{After: time.Hour},
+		Topics: map[string]Retention{
+			"archived": {After: time.Minute, Archive: true},
+			"forever":  {},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		topic       string
+		wantPurged  bool
+		wantArchive bool
+		wantAfter   time.Duration
+	}{
+		{name: "Default retention", topic: "t1", wantPurged: true, wantAfter: time.Hour},
+		{name: "Topic override", topic: "archived", wantPurged: true, wantArchive: true, wantAfter: time.Minute},
+		{name: "Kept forever", topic: "forever", wantPurged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &purgerEngine{}
+			w := NewWorker(nil, engine, WithRetention(policy))
+
+			before := time.Now()
+			w.purge(context.Background(), tt.topic)
+
+			if got := len(engine.requests) == 1; got != tt.wantPurged {
+				t.Fatalf("purge() purged = %v, want %v", got, tt.wantPurged)
+			}
+			if !tt.wantPurged {
+				return
+			}
+
+			req := engine.requests[0]
+			cutoff := before.Add(-tt.wantAfter).UnixMilli()
+			if req.Topic != tt.topic || req.Archive != tt.wantArchive || req.BatchSize != defaultPurgeBatchSize || req.CompletedBefore < cutoff {
+				t.Errorf("purge() got = %+v, want topic=%s archive=%v before>=%d", req, tt.topic, tt.wantArchive, cutoff)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-zookeeper/zk"
@@ -37,9 +38,22 @@ type (
 		zkServers []string      // zkServers: slice of zookeeper servers to connect to
 		timeout   time.Duration // timeout: zookeeper connection timeout
 		conn      *zk.Conn      // conn zookeeper connection
-		ldrDoneCh chan any      // ldrDoneCh inform the leader to stop watch on membership node
+		ldrDoneCh chan any      // ldrDoneCh is closed to inform the leader to stop watches on the membership and admin nodes
 		role      election.Role // role: role assumed by the worker
 
+		authScheme string   // authScheme: zookeeper auth scheme, only digest is supported by the zk client
+		authCreds  []byte   // authCreds: credentials for the auth scheme, user:password for digest
+		acl        []zk.ACL // acl: acl applied to all the znodes created by the worker
+
+		meta Metadata // meta: metadata advertised in the membership znode
+
+		retention *RetentionPolicy // retention: purging of completed jobs, nil keeps them forever
+
+		longPolling LongPolling // longPolling: waiting of the dequeue calls with a WaitTimeout
+		waiters     *waiters    // waiters: dequeue calls waiting for a job per topic
+
+		rebalances int64 // rebalances: number of partitions written while leader, updated atomically
+
 		logger commons.Logger // logger
 	}
 
@@ -50,6 +64,7 @@ type (
 
 var (
 	errorNoAssignedTopics = errors.New("no assigned topics")
+	errorSaslNotSupported = errors.New("sasl auth is not supported by the zookeeper client, use digest auth")
 )
 
 func WithTimeout(timeout time.Duration) Option {
@@ -70,6 +85,31 @@ func WithNamespace(namespace string) Option {
 	}
 }
 
+// WithDigestAuth : Authenticates the zookeeper session with the digest scheme.
+// Unless overridden by WithACL all the znodes are restricted to the authenticated user
+func WithDigestAuth(user, password string) Option {
+	return func(w *Worker) {
+		w.authScheme = "digest"
+		w.authCreds = []byte(fmt.Sprintf("%s:%s", user, password))
+	}
+}
+
+// WithAuth : Authenticates the zookeeper session with an arbitrary scheme supported by the server through AddAuth.
+// SASL needs a handshake the zookeeper client does not implement, Start fails with the sasl scheme
+func WithAuth(scheme string, creds []byte) Option {
+	return func(w *Worker) {
+		w.authScheme = scheme
+		w.authCreds = creds
+	}
+}
+
+// WithACL : Sets the acl applied to the namespace, members, election and partition znodes
+func WithACL(acl []zk.ACL) Option {
+	return func(w *Worker) {
+		w.acl = acl
+	}
+}
+
 func WithLogger(logger commons.Logger) Option {
 	return func(w *Worker) {
 		w.logger = logger
@@ -89,34 +129,61 @@ func NewWorker(servers []string, engine api.Engine, opts ...Option) *Worker {
 		role:      election.FOLLOWER,
 		done:      make(chan bool),
 		logger:    &commons.DefaultLogger{},
+
+		longPolling: LongPolling{PollInterval: defaultPollInterval, MaxWait: defaultMaxWait},
+		waiters:     newWaiters(),
 	}
 
 	for _, opt := range opts {
 		opt(w)
 	}
+
+	if w.acl == nil {
+		w.acl = zk.WorldACL(zk.PermAll)
+		if w.authScheme != "" {
+			w.acl = zk.AuthACL(zk.PermAll)
+		}
+	}
 	return w
 }
 
 // Start : Registers the instance with a new zookeeper
-// TODO: change this to use AuthACL and validate prio instances
+// TODO: validate prio instances
 func (w *Worker) Start(ctx context.Context) error {
+	if w.authScheme == "sasl" {
+		return errorSaslNotSupported
+	}
+
 	conn, zkCh, err := zk.Connect(w.zkServers, w.timeout, zk.WithLogger(w.logger))
 	if err != nil {
 		return err
 	}
 
 	w.conn = conn
+	if w.authScheme != "" {
+		// zk client re-sends the credentials on every reconnect
+		err = w.conn.AddAuth(w.authScheme, w.authCreds)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = w.ensureZNodes()
 	if err != nil {
 		return err
 	}
 
-	_, err = w.conn.Create(fmt.Sprintf(memberNode, w.Namespace, w.ID), []byte{}, zk.FlagEphemeral, zk.WorldACL(zk.PermAll))
+	meta, err := json.Marshal(w.meta)
 	if err != nil {
 		return err
 	}
 
-	elector, err := election.NewElector(conn, fmt.Sprintf(electionRoot, w.Namespace), w.logger)
+	_, err = w.conn.Create(fmt.Sprintf(memberNode, w.Namespace, w.ID), meta, zk.FlagEphemeral, w.acl)
+	if err != nil {
+		return err
+	}
+
+	elector, err := election.NewElector(conn, fmt.Sprintf(electionRoot, w.Namespace), w.logger, election.WithACL(w.acl))
 	if err != nil {
 		return err
 	}
@@ -175,10 +242,30 @@ func (w *Worker) ShutDown() bool {
 	return true
 }
 
+// Admin : Returns an admin for the worker's namespace sharing the worker's zookeeper session
+func (w *Worker) Admin() *Admin {
+	return NewAdmin(w.conn, w.Namespace, w.acl)
+}
+
 func (w *Worker) IsLeader() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
 	return w.role == election.LEADER
 }
 
+// Rebalances : Returns the number of times the worker re-balanced the topics as the leader
+func (w *Worker) Rebalances() int64 {
+	return atomic.LoadInt64(&w.rebalances)
+}
+
+// ZkState : Returns the state of the zookeeper session, disconnected before Start
+func (w *Worker) ZkState() zk.State {
+	if w.conn == nil {
+		return zk.StateDisconnected
+	}
+	return w.conn.State()
+}
+
 func (w *Worker) ensureZNodes() error {
 	err := w.ensureZnodePath(fmt.Sprintf(nsPath, w.Namespace))
 	if err != nil {
@@ -200,13 +287,33 @@ func (w *Worker) ensureZNodes() error {
 		return err
 	}
 
+	err = w.ensureZnodePath(fmt.Sprintf(placementRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
+	err = w.ensureZnodePath(fmt.Sprintf(pinRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
+	err = w.ensureZnodePath(fmt.Sprintf(cordonRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ensureZnodePath : Creates the znode with the worker acl, a znode created earlier gets the acl re-applied
+// so that enabling auth restricts the znodes of an existing namespace
 func (w *Worker) ensureZnodePath(path string) error {
-	_, err := w.conn.Create(path, []byte{}, 0, zk.WorldACL(zk.PermAll))
-	if err != nil && err != zk.ErrNodeExists {
-		w.logger.Error(err, "failed to create Namespace key")
+	_, err := w.conn.Create(path, []byte{}, 0, w.acl)
+	if err == zk.ErrNodeExists {
+		_, err = w.conn.SetACL(path, w.acl, -1)
+	}
+	if err != nil {
+		w.logger.Error(err, "failed to ensure znode path=%s", path)
 		return err
 	}
 	return nil
@@ -218,6 +325,22 @@ func (w *Worker) leaderSetup() error {
 		return err
 	}
 
+	// placements, pins and cordons are watched along with the members so that their changes are applied right away
+	_, _, placementsCh, err := w.conn.ChildrenW(fmt.Sprintf(placementRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
+	_, _, pinsCh, err := w.conn.ChildrenW(fmt.Sprintf(pinRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
+	_, _, cordonsCh, err := w.conn.ChildrenW(fmt.Sprintf(cordonRoot, w.Namespace))
+	if err != nil {
+		return err
+	}
+
 	w.logger.Info("re-balancing children")
 	err = w.reBalanceChildren(children)
 	if err != nil {
@@ -225,10 +348,13 @@ func (w *Worker) leaderSetup() error {
 		return err
 	}
 
-	go w.watchMembers(membersCh)
-
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	w.ldrDoneCh = make(chan any)
+	go w.watchChildren(fmt.Sprintf(membershipRoot, w.Namespace), membersCh, w.ldrDoneCh)
+	go w.watchChildren(fmt.Sprintf(placementRoot, w.Namespace), placementsCh, w.ldrDoneCh)
+	go w.watchChildren(fmt.Sprintf(pinRoot, w.Namespace), pinsCh, w.ldrDoneCh)
+	go w.watchChildren(fmt.Sprintf(cordonRoot, w.Namespace), cordonsCh, w.ldrDoneCh)
 	w.role = election.LEADER
 	return err
 
@@ -239,7 +365,7 @@ func (w *Worker) followerSetup() {
 	defer w.mu.Unlock()
 	if w.role == election.LEADER {
 		w.role = election.FOLLOWER
-		w.ldrDoneCh <- nil
+		close(w.ldrDoneCh)
 	}
 }
 
@@ -269,9 +395,12 @@ func (w *Worker) maintain() error {
 				})
 				if err != nil {
 					w.logger.Error(err, "failed for requeue jobs for topic %s", tName)
-					return
+				} else {
+					w.logger.Info("re-queued jobs=%d, topic=%s", count, tName)
 				}
-				w.logger.Info("re-queued jobs=%d, topic=%s", count, tName)
+
+				// purging does not depend on the re-queue, a failing re-queue must not let completed jobs pile up
+				w.purge(context.Background(), tName)
 			}(topic)
 		}
 	}
@@ -279,38 +408,39 @@ func (w *Worker) maintain() error {
 	return nil
 }
 
-func (w *Worker) watchMembers(ch <-chan zk.Event) {
-	w.logger.Info("watchMembers: setting up member watch")
+// watchChildren : Re-balances the topics every time the children of the path change until done is closed
+func (w *Worker) watchChildren(path string, ch <-chan zk.Event, done chan any) {
+	w.logger.Info("watchChildren: setting up watch path=%s", path)
 	for {
 		select {
 		case event := <-ch:
-			w.logger.Info("watchMembers: event received=%v", event)
+			w.logger.Info("watchChildren: event received=%v", event)
 			if event.Type == zk.EventNodeChildrenChanged {
 				err := w.reBalance()
 				if err != nil {
-					w.logger.Error(err, "watchMembers: re-balance error")
+					w.logger.Error(err, "watchChildren: re-balance error")
 				}
 
-				err = w.reWatchMembers()
+				err = w.reWatchChildren(path, done)
 				if err != nil {
-					w.logger.Error(err, "watchMembers: reWatchMembers error")
+					w.logger.Error(err, "watchChildren: reWatchChildren error")
 				}
 				return
 			}
-		case <-w.ldrDoneCh:
-			w.logger.Info("watchMembers: stopping watch on the members")
+		case <-done:
+			w.logger.Info("watchChildren: stopping watch path=%s", path)
 			return
 		}
 	}
 }
 
-func (w *Worker) reWatchMembers() error {
-	w.logger.Info("reWatchMembers: setting up member watch")
-	_, _, membersCh, err := w.conn.ChildrenW(fmt.Sprintf(membershipRoot, w.Namespace))
+func (w *Worker) reWatchChildren(path string, done chan any) error {
+	w.logger.Info("reWatchChildren: setting up watch path=%s", path)
+	_, _, ch, err := w.conn.ChildrenW(path)
 	if err != nil {
 		return err
 	}
-	go w.watchMembers(membersCh)
+	go w.watchChildren(path, ch, done)
 	return err
 }
 
@@ -328,8 +458,23 @@ func (w *Worker) reBalanceChildren(children []string) error {
 		return err
 	}
 
-	w.logger.Info("balancing %d topics among %d workers", len(topics), len(children))
-	data, err := json.Marshal(calculatePartition(topics, children))
+	members, err := w.members(children)
+	if err != nil {
+		return err
+	}
+
+	rules, err := w.assignmentRules()
+	if err != nil {
+		return err
+	}
+
+	w.logger.Info("balancing %d topics among %d workers", len(topics), len(members))
+	partition, unassigned := calculatePlacement(topics, members, rules)
+	if len(unassigned) > 0 {
+		w.logger.Warn("no worker available for topics=%v", unassigned)
+	}
+
+	data, err := json.Marshal(partition)
 	if err != nil {
 		return err
 	}
@@ -340,18 +485,24 @@ func (w *Worker) reBalanceChildren(children []string) error {
 		return err
 	}
 
+	atomic.AddInt64(&w.rebalances, 1)
 	return nil
 }
 
 func calculatePartition(topics, children []string) membershipData {
 	partition := membershipData(make(map[string]map[string]bool))
+	for _, child := range children {
+		partition[child] = make(map[string]bool)
+	}
+
 	if len(topics) > 0 && len(children) > 0 {
 		tpw := int(math.Round(float64(len(topics)) / float64(len(children))))
 		i := 0
 		for ; i < len(children)-1; i++ {
-			partition[children[i]] = topicsToMap(topics[i*tpw : (i+1)*tpw])
+			start, end := bound(i*tpw, len(topics)), bound((i+1)*tpw, len(topics))
+			partition[children[i]] = topicsToMap(topics[start:end])
 		}
-		partition[children[i]] = topicsToMap(topics[i*tpw:])
+		partition[children[i]] = topicsToMap(topics[bound(i*tpw, len(topics)):])
 	}
 
 	return partition
@@ -364,3 +515,10 @@ func topicsToMap(topics []string) map[string]bool {
 	}
 	return assignments
 }
+
+func bound(i, max int) int {
+	if i > max {
+		return max
+	}
+	return i
+}
@@ -1,8 +1,11 @@
 package core
 
 import (
+	"context"
 	"reflect"
 	"testing"
+
+	"github.com/go-zookeeper/zk"
 )
 
 func Test_calculatePartition(t *testing.T) {
@@ -50,6 +53,22 @@ func Test_calculatePartition(t *testing.T) {
 				"c3": {},
 			},
 		},
+		{
+			name: "Rounded up partition",
+			args: args{
+				topics:   []string{"t1", "t2", "t3", "t4"},
+				children: []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7"},
+			},
+			want: map[string]map[string]bool{
+				"c1": {"t1": true},
+				"c2": {"t2": true},
+				"c3": {"t3": true},
+				"c4": {"t4": true},
+				"c5": {},
+				"c6": {},
+				"c7": {},
+			},
+		},
 		{
 			name: "No workers",
 			args: args{
@@ -80,3 +99,51 @@ func Test_calculatePartition(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWorker_auth(t *testing.T) {
+	custom := zk.DigestACL(zk.PermAll, "admin", "secret")
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantCreds string
+		wantACL   []zk.ACL
+	}{
+		{
+			name:    "No auth",
+			wantACL: zk.WorldACL(zk.PermAll),
+		},
+		{
+			name:      "Digest auth restricts the znodes",
+			opts:      []Option{WithDigestAuth("prio", "secret")},
+			wantCreds: "prio:secret",
+			wantACL:   zk.AuthACL(zk.PermAll),
+		},
+		{
+			name:      "Acl overrides the auth default",
+			opts:      []Option{WithDigestAuth("prio", "secret"), WithACL(custom)},
+			wantCreds: "prio:secret",
+			wantACL:   custom,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorker(nil, nil, tt.opts...)
+			if string(w.authCreds) != tt.wantCreds {
+				t.Errorf("NewWorker() creds = %s, want %s", w.authCreds, tt.wantCreds)
+			}
+			if !reflect.DeepEqual(w.acl, tt.wantACL) {
+				t.Errorf("NewWorker() acl = %v, want %v", w.acl, tt.wantACL)
+			}
+			if got := w.Admin().acl; !reflect.DeepEqual(got, tt.wantACL) {
+				t.Errorf("Admin() acl = %v, want %v", got, tt.wantACL)
+			}
+		})
+	}
+}
+
+func TestWorker_Start_Sasl(t *testing.T) {
+	w := NewWorker([]string{"127.0.0.1"}, nil, WithAuth("sasl", []byte("prio")))
+	if err := w.Start(context.Background()); err != errorSaslNotSupported {
+		t.Errorf("Start() err=%v, want %v", err, errorSaslNotSupported)
+	}
+}
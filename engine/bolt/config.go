@@ -0,0 +1,9 @@
+package bolt
+
+import "time"
+
+type Config struct {
+	Path        string        // Path: database file, created if it does not exist
+	NoSync      bool          // NoSync: skips fsync after every commit, faster but jobs can be lost on a crash
+	LockTimeout time.Duration // LockTimeout: time to wait for the file lock held by another process, waits forever if 0
+}
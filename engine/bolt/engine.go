@@ -0,0 +1,401 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/bolt/internal/keys"
+	"github.com/hextechpal/prio/engine/bolt/internal/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	topicsBucket  = []byte("topics")  // topics: topic name -> description
+	jobsBucket    = []byte("jobs")    // jobs: job id -> job
+	pendingBucket = []byte("pending") // pending: topic -> (inverted priority, sequence) -> job id
+	claimedBucket = []byte("claimed") // claimed: topic -> (claimed at, job id) -> nil
+
+	errorTopicExists        = errors.New("topic already registered")
+	errorTopicNotRegistered = errors.New("topic not registered")
+)
+
+type (
+	// Engine : Storage engine on top of an embedded bbolt database.
+	// Pending jobs of a topic are laid out so that Dequeue is a seek to the first key
+	// and claimed jobs are ordered by their claim time so that ReQueue is a range scan
+	Engine struct {
+		db     *bolt.DB
+		config Config
+		logger commons.Logger
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+// NewEngine : Opens (or creates) the database file and the top level buckets
+func NewEngine(config Config, opts ...Option) (*Engine, error) {
+	db, err := bolt.Open(config.Path, 0600, &bolt.Options{Timeout: config.LockTimeout, NoSync: config.NoSync})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{topicsBucket, jobsBucket, pendingBucket, claimedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s := &Engine{
+		db:     db,
+		config: config,
+		logger: &commons.DefaultLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *Engine) Close() error {
+	return s.db.Close()
+}
+
+func (s *Engine) GetTopics(_ context.Context) ([]string, error) {
+	topics := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(topicsBucket).ForEach(func(k, _ []byte) error {
+			topics = append(topics, string(k))
+			return nil
+		})
+	})
+	return topics, err
+}
+
+func (s *Engine) RegisterTopic(_ context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		topics := tx.Bucket(topicsBucket)
+		if topics.Get([]byte(req.Name)) != nil {
+			return errorTopicExists
+		}
+
+		if _, err := tx.Bucket(pendingBucket).CreateBucketIfNotExists([]byte(req.Name)); err != nil {
+			return err
+		}
+
+		if _, err := tx.Bucket(claimedBucket).CreateBucketIfNotExists([]byte(req.Name)); err != nil {
+			return err
+		}
+		return topics.Put([]byte(req.Name), []byte(req.Description))
+	})
+	if err != nil {
+		return api.RegisterTopicResponse{}, err
+	}
+	s.logger.Info("topic %s registered successfully id", req.Name)
+	return api.RegisterTopicResponse{}, nil
+}
+
+func (s *Engine) Enqueue(_ context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	var id int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket).Bucket([]byte(req.Topic))
+		if pending == nil {
+			return errorTopicNotRegistered
+		}
+
+		jobs := tx.Bucket(jobsBucket)
+		seq, err := jobs.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		id = int64(seq)
+		job := &models.Job{
+			ID:        id,
+			Topic:     req.Topic,
+			Payload:   req.Payload,
+			Headers:   req.Headers,
+			Priority:  req.Priority,
+			Status:    models.PENDING,
+			Seq:       seq,
+			CreatedAt: time.Now().UnixMilli(),
+			UpdatedAt: time.Now().UnixMilli(),
+		}
+
+		if err = putJob(jobs, job); err != nil {
+			return err
+		}
+		return pending.Put(keys.Pending(job.Priority, job.Seq), keys.ID(id))
+	})
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+	s.logger.Info("job enqueued id=%d", id)
+	return api.EnqueueResponse{JobId: id}, nil
+}
+
+func (s *Engine) Dequeue(_ context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	var job *models.Job
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket).Bucket([]byte(req.Topic))
+		if pending == nil {
+			return errorTopicNotRegistered
+		}
+
+		c := pending.Cursor()
+		k, v := c.First()
+		if k == nil {
+			return nil
+		}
+
+		jobs := tx.Bucket(jobsBucket)
+		var err error
+		job, err = getJob(jobs, keys.DecodeID(v))
+		if err != nil {
+			return err
+		}
+
+		if err = c.Delete(); err != nil {
+			return err
+		}
+
+		job.Status = models.CLAIMED
+		job.ClaimedAt = time.Now().UnixMilli()
+		job.ClaimedBy = req.Consumer
+		if err = putJob(jobs, job); err != nil {
+			return err
+		}
+		return tx.Bucket(claimedBucket).Bucket([]byte(req.Topic)).Put(keys.Claimed(job.ClaimedAt, job.ID), nil)
+	})
+	if err != nil || job == nil {
+		return api.DequeueResponse{}, err
+	}
+
+	return api.DequeueResponse{
+		JobId:    job.ID,
+		Topic:    job.Topic,
+		Payload:  job.Payload,
+		Headers:  job.Headers,
+		Priority: job.Priority,
+	}, nil
+}
+
+func (s *Engine) GetJob(_ context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	var job *models.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		job, err = getJob(tx.Bucket(jobsBucket), req.JobId)
+		return err
+	})
+	if err != nil {
+		return api.GetJobResponse{}, err
+	}
+	return api.GetJobResponse{
+		JobId:     job.ID,
+		Topic:     job.Topic,
+		Payload:   job.Payload,
+		Priority:  job.Priority,
+		Headers:   job.Headers,
+		Status:    api.JobStatus(job.Status),
+		ClaimedBy: job.ClaimedBy,
+	}, nil
+}
+
+func (s *Engine) Ack(_ context.Context, req api.AckRequest) (api.AckResponse, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		jobs := tx.Bucket(jobsBucket)
+		job, err := claimedJob(jobs, req.JobId, req.Consumer)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Bucket(claimedBucket).Bucket([]byte(job.Topic)).Delete(keys.Claimed(job.ClaimedAt, job.ID)); err != nil {
+			return err
+		}
+
+		job.Status = models.COMPLETED
+		job.CompletedAt = time.Now().UnixMilli()
+		return putJob(jobs, job)
+	})
+	if err != nil {
+		return api.AckResponse{Acked: false}, err
+	}
+	return api.AckResponse{Acked: true}, nil
+}
+
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (s *Engine) ExtendLease(_ context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		jobs := tx.Bucket(jobsBucket)
+		job, err := claimedJob(jobs, req.JobId, req.Consumer)
+		if err != nil {
+			return err
+		}
+
+		claimed := tx.Bucket(claimedBucket).Bucket([]byte(job.Topic))
+		if err = claimed.Delete(keys.Claimed(job.ClaimedAt, job.ID)); err != nil {
+			return err
+		}
+
+		job.ClaimedAt = time.Now().UnixMilli()
+		if err = putJob(jobs, job); err != nil {
+			return err
+		}
+		return claimed.Put(keys.Claimed(job.ClaimedAt, job.ID), nil)
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// Nack : Queues the job behind the pending jobs of the same priority, like an expired job
+func (s *Engine) Nack(_ context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		jobs := tx.Bucket(jobsBucket)
+		job, err := claimedJob(jobs, req.JobId, req.Consumer)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Bucket(claimedBucket).Bucket([]byte(job.Topic)).Delete(keys.Claimed(job.ClaimedAt, job.ID)); err != nil {
+			return err
+		}
+
+		seq, err := jobs.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		job.Status = models.PENDING
+		job.Seq = seq
+		job.ClaimedAt = 0
+		job.ClaimedBy = ""
+		job.UpdatedAt = time.Now().UnixMilli()
+		if err = putJob(jobs, job); err != nil {
+			return err
+		}
+		return tx.Bucket(pendingBucket).Bucket([]byte(job.Topic)).Put(keys.Pending(job.Priority, job.Seq), keys.ID(job.ID))
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+func (s *Engine) ReQueue(_ context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	count := int64(0)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		claimed := tx.Bucket(claimedBucket).Bucket([]byte(req.Topic))
+		if claimed == nil {
+			return errorTopicNotRegistered
+		}
+		pending := tx.Bucket(pendingBucket).Bucket([]byte(req.Topic))
+		jobs := tx.Bucket(jobsBucket)
+
+		c := claimed.Cursor()
+		for k, _ := c.First(); k != nil && keys.ClaimedAt(k) < req.RequeueTs; k, _ = c.First() {
+			job, err := getJob(jobs, keys.DecodeID(k[8:]))
+			if err != nil {
+				return err
+			}
+
+			// re-queued jobs go behind the pending jobs of the same priority
+			seq, err := jobs.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			job.Status = models.PENDING
+			job.Seq = seq
+			job.ClaimedAt = 0
+			job.ClaimedBy = ""
+			job.UpdatedAt = time.Now().UnixMilli()
+			if err = putJob(jobs, job); err != nil {
+				return err
+			}
+
+			if err = pending.Put(keys.Pending(job.Priority, job.Seq), keys.ID(job.ID)); err != nil {
+				return err
+			}
+
+			if err = c.Delete(); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return api.RequeueResponse{}, err
+	}
+	return api.RequeueResponse{Count: count}, nil
+}
+
+// PendingCount : Returns the number of pending jobs of the topic, zero for an unknown topic
+func (s *Engine) PendingCount(_ context.Context, topic string) (int64, error) {
+	var count int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if pending := tx.Bucket(pendingBucket).Bucket([]byte(topic)); pending != nil {
+			count = int64(pending.Stats().KeyN)
+		}
+		return nil
+	})
+	return count, err
+}
+
+func getJob(jobs *bolt.Bucket, id int64) (*models.Job, error) {
+	data := jobs.Get(keys.ID(id))
+	if data == nil {
+		return nil, api.ErrorJobNotPresent
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// claimedJob : Returns the job if it is claimed by the consumer, the error tells why otherwise
+func claimedJob(jobs *bolt.Bucket, id int64, consumer string) (*models.Job, error) {
+	job, err := getJob(jobs, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case job.Status == models.COMPLETED:
+		return nil, api.ErrorAlreadyAcked
+	case job.Status == models.PENDING:
+		return nil, api.ErrorLeaseExceeded
+	case job.ClaimedBy != consumer:
+		return nil, api.ErrorWrongConsumer
+	}
+	return job, nil
+}
+
+func putJob(jobs *bolt.Bucket, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return jobs.Put(keys.ID(job.ID), data)
+}
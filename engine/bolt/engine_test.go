@@ -0,0 +1,50 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+)
+
+func newTestEngine(t *testing.T, path string) *Engine {
+	t.Helper()
+	s, err := NewEngine(Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewEngine() err=%v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		return newTestEngine(t, filepath.Join(t.TempDir(), "prio.bolt"))
+	})
+}
+
+func TestEngine_Durable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prio.bolt")
+	ctx := context.Background()
+
+	s, err := NewEngine(Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewEngine() err=%v", err)
+	}
+	_, _ = s.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	enq, _ := s.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	_ = s.Close()
+
+	s = newTestEngine(t, path)
+	topics, err := s.GetTopics(ctx)
+	if err != nil || len(topics) != 1 || topics[0] != "t1" {
+		t.Fatalf("GetTopics() after restart got = %v, err=%v", topics, err)
+	}
+
+	res, err := s.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	if err != nil || res.JobId != enq.JobId {
+		t.Errorf("Dequeue() after restart got = %v, want job=%d, err=%v", res, enq.JobId, err)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/hextechpal/prio/engine/bolt
+
+go 1.19
+
+require (
+	github.com/hextechpal/prio/core v0.0.0-20221125150718-3fe15c6f3658
+	go.etcd.io/bbolt v1.3.7
+)
+
+require (
+	github.com/google/uuid v1.3.0 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+)
+
+replace github.com/hextechpal/prio/core => ../../core
@@ -0,0 +1,38 @@
+package keys
+
+import "encoding/binary"
+
+// Pending : Key of a job in the pending bucket of a topic.
+// The priority is inverted so that a forward cursor visits the highest priority first,
+// jobs of same priority are ordered by their sequence
+func Pending(priority int32, seq uint64) []byte {
+	k := make([]byte, 12)
+	binary.BigEndian.PutUint32(k[:4], ^(uint32(priority) ^ 1<<31))
+	binary.BigEndian.PutUint64(k[4:], seq)
+	return k
+}
+
+// Claimed : Key of a job in the claimed bucket of a topic, ordered by the claim time
+func Claimed(claimedAt int64, jobId int64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k[:8], uint64(claimedAt))
+	binary.BigEndian.PutUint64(k[8:], uint64(jobId))
+	return k
+}
+
+// ClaimedAt : Claim time encoded in a claimed key
+func ClaimedAt(k []byte) int64 {
+	return int64(binary.BigEndian.Uint64(k[:8]))
+}
+
+// ID : Encodes a job id as a bucket key
+func ID(id int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(id))
+	return k
+}
+
+// DecodeID : Decodes a job id stored by ID
+func DecodeID(k []byte) int64 {
+	return int64(binary.BigEndian.Uint64(k))
+}
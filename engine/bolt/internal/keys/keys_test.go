@@ -0,0 +1,39 @@
+package keys
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPending_Order(t *testing.T) {
+	// expected dequeue order
+	ordered := [][]byte{
+		Pending(math.MaxInt32, 1),
+		Pending(10, 1),
+		Pending(10, 2),
+		Pending(1, 0),
+		Pending(0, 5),
+		Pending(-1, 3),
+		Pending(math.MinInt32, 0),
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		if bytes.Compare(ordered[i-1], ordered[i]) >= 0 {
+			t.Errorf("Pending() key %d = %x should sort before key %d = %x", i-1, ordered[i-1], i, ordered[i])
+		}
+	}
+}
+
+func TestClaimed(t *testing.T) {
+	k := Claimed(1669391238000, 42)
+	if got := ClaimedAt(k); got != 1669391238000 {
+		t.Errorf("ClaimedAt() got = %d, want %d", got, 1669391238000)
+	}
+	if got := DecodeID(k[8:]); got != 42 {
+		t.Errorf("DecodeID() got = %d, want %d", got, 42)
+	}
+	if bytes.Compare(Claimed(1, 99), Claimed(2, 1)) >= 0 {
+		t.Errorf("Claimed() keys should be ordered by claim time")
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+type Status int
+
+const (
+	PENDING   Status = iota // Represents the job is just inserted in to the database
+	CLAIMED                 // The job has been claimed by the consumer
+	COMPLETED               // The job has been marked completed by the consumer
+)
+
+type Job struct {
+	ID       int64             `json:"id"`
+	Topic    string            `json:"topic"`
+	Payload  []byte            `json:"payload"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Priority int32             `json:"priority"`
+	Status   Status            `json:"status"`
+
+	Seq uint64 `json:"seq"` // Seq: position of the job among the pending jobs of same priority
+
+	ClaimedAt int64  `json:"claimed_at"`
+	ClaimedBy string `json:"claimed_by"`
+
+	CompletedAt int64 `json:"completed_at"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"encoding/json"
+	"github.com/hextechpal/prio/engine/memory/internal/heap"
+	"github.com/hextechpal/prio/engine/memory/internal/models"
+	"github.com/hextechpal/prio/engine/memory/internal/wal"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	walFile      = "prio.wal"
+	snapshotFile = "prio.snapshot"
+)
+
+const (
+	SyncAlways   SyncPolicy = iota // SyncAlways: fsync after every operation, nothing acknowledged is lost
+	SyncInterval                   // SyncInterval: fsync periodically, operations since the last fsync can be lost on a crash
+	SyncNever                      // SyncNever: leave flushing to the os, only survives process crashes
+)
+
+const (
+	opRegisterTopic op = iota
+	opEnqueue
+	opDequeue
+	opAck
+	opRequeue
+	opExtend
+	opPurge
+)
+
+type (
+	SyncPolicy int
+
+	// WALConfig : Makes the engine durable by logging every mutation to a write ahead log in Dir
+	// and periodically compacting the log into a snapshot of the topics and jobs
+	WALConfig struct {
+		Dir              string        // Dir: directory holding the log and the snapshot, created if it does not exist
+		Sync             SyncPolicy    // Sync: fsync policy for the log
+		SyncInterval     time.Duration // SyncInterval: fsync interval for SyncInterval, defaults to 1s
+		SnapshotInterval time.Duration // SnapshotInterval: interval between snapshots, defaults to 1m
+	}
+
+	op int
+
+	// record : A single mutation in the write ahead log
+	record struct {
+		Op       op          `json:"op"`
+		Topic    string      `json:"topic,omitempty"`
+		Job      *models.Job `json:"job,omitempty"`
+		JobIds   []int64     `json:"job_ids,omitempty"`
+		Consumer string      `json:"consumer,omitempty"`
+		Ts       int64       `json:"ts,omitempty"`
+	}
+
+	snapshot struct {
+		Topics []string      `json:"topics"`
+		Jobs   []*models.Job `json:"jobs"`
+	}
+)
+
+// WithWAL : Enables the write ahead log, the existing snapshot and log in the directory are replayed by NewEngine
+func WithWAL(config WALConfig) Option {
+	return func(m *Engine) {
+		if config.SyncInterval == 0 {
+			config.SyncInterval = time.Second
+		}
+		if config.SnapshotInterval == 0 {
+			config.SnapshotInterval = time.Minute
+		}
+		m.walConfig = &config
+	}
+}
+
+// open : Restores the state from the snapshot and the log and starts the background sync and snapshot routine
+func (m *Engine) open() error {
+	if err := os.MkdirAll(m.walConfig.Dir, 0700); err != nil {
+		return err
+	}
+
+	if err := m.loadSnapshot(); err != nil {
+		return err
+	}
+
+	// The log holds every mutation since the last snapshot. If a crash happened after the snapshot was written
+	// but before the log was reset the records are replayed on top of the snapshot, which converges to the same state
+	// as every job is re-created by its enqueue record and then brought to its latest state by the following records
+	log, err := wal.Open(filepath.Join(m.walConfig.Dir, walFile), m.walConfig.Sync == SyncAlways, m.replay)
+	if err != nil {
+		return err
+	}
+	m.wal = log
+	m.rebuildHeaps()
+
+	m.done = make(chan bool)
+	go m.background()
+	return nil
+}
+
+// Close : Snapshots the state and closes the write ahead log, it is a no-op for a non-durable engine
+func (m *Engine) Close() error {
+	if m.wal == nil {
+		return nil
+	}
+	close(m.done)
+	if err := m.Snapshot(); err != nil {
+		return err
+	}
+	return m.wal.Close()
+}
+
+// Snapshot : Writes all the topics and jobs to the snapshot file and discards the log
+func (m *Engine) Snapshot() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := snapshot{Topics: make([]string, 0, len(m.topicsMap)), Jobs: make([]*models.Job, 0, len(m.jobMap))}
+	for topic := range m.topicsMap {
+		s.Topics = append(s.Topics, topic)
+	}
+	for _, job := range m.jobMap {
+		s.Jobs = append(s.Jobs, job)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err = wal.WriteFile(filepath.Join(m.walConfig.Dir, snapshotFile), data); err != nil {
+		return err
+	}
+	return m.wal.Reset()
+}
+
+func (m *Engine) background() {
+	syncTicker := time.NewTicker(m.walConfig.SyncInterval)
+	defer syncTicker.Stop()
+	snapshotTicker := time.NewTicker(m.walConfig.SnapshotInterval)
+	defer snapshotTicker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-syncTicker.C:
+			if m.walConfig.Sync != SyncInterval {
+				continue
+			}
+			if err := m.wal.Sync(); err != nil {
+				m.logger.Error(err, "error syncing wal")
+			}
+		case <-snapshotTicker.C:
+			if err := m.Snapshot(); err != nil {
+				m.logger.Error(err, "error writing snapshot")
+			}
+		}
+	}
+}
+
+// log : Appends the record to the write ahead log, callers hold the lock
+func (m *Engine) log(r record) error {
+	if m.wal == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return m.wal.Append(data)
+}
+
+func (m *Engine) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(m.walConfig.Dir, snapshotFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var s snapshot
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for _, topic := range s.Topics {
+		m.topicsMap[topic] = nil
+	}
+	for _, job := range s.Jobs {
+		m.jobMap[job.ID] = job
+		if job.Seq > m.seq {
+			m.seq = job.Seq
+		}
+		if job.ID > m.lastId {
+			m.lastId = job.ID
+		}
+	}
+	return nil
+}
+
+// replay : Applies a logged mutation to the jobs, the heaps are rebuilt once the log is replayed
+func (m *Engine) replay(data []byte) error {
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	switch r.Op {
+	case opRegisterTopic:
+		m.topicsMap[r.Topic] = nil
+	case opEnqueue:
+		job := *r.Job
+		m.jobMap[job.ID] = &job
+		if job.Seq > m.seq {
+			m.seq = job.Seq
+		}
+		if job.ID > m.lastId {
+			m.lastId = job.ID
+		}
+	case opDequeue:
+		for _, id := range r.JobIds {
+			if job, ok := m.jobMap[id]; ok {
+				claim(job, r.Consumer, r.Ts)
+			}
+		}
+	case opAck:
+		for _, id := range r.JobIds {
+			if job, ok := m.jobMap[id]; ok {
+				complete(job, r.Ts)
+			}
+		}
+	case opExtend:
+		for _, id := range r.JobIds {
+			if job, ok := m.jobMap[id]; ok {
+				job.ClaimedAt = r.Ts
+			}
+		}
+	case opPurge:
+		for _, id := range r.JobIds {
+			delete(m.jobMap, id)
+		}
+	case opRequeue:
+		// sequences are handed out in the logged order, same as when the records were written
+		for _, id := range r.JobIds {
+			m.seq++
+			if job, ok := m.jobMap[id]; ok {
+				release(job, m.seq)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Engine) rebuildHeaps() {
+	for topic := range m.topicsMap {
+		m.topicsMap[topic] = heap.NewMaxHeap[node](defaultCapacity)
+	}
+
+	for _, job := range m.jobMap {
+		h, ok := m.topicsMap[job.Topic]
+		if !ok || job.Status != models.PENDING {
+			continue
+		}
+		if err := h.Insert(node{jobId: job.ID, priority: job.Priority, seq: job.Seq}); err != nil {
+			m.logger.Error(err, "error restoring job=%d", job.ID)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+func newDurableEngine(t *testing.T, dir string) *Engine {
+	t.Helper()
+	m, err := NewEngine(WithWAL(WALConfig{Dir: dir, Sync: SyncAlways, SnapshotInterval: time.Hour}))
+	if err != nil {
+		t.Fatalf("NewEngine() err=%v", err)
+	}
+	return m
+}
+
+func populate(t *testing.T, m *Engine) (claimed, pending int64) {
+	t.Helper()
+	ctx := context.Background()
+	_, _ = m.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	for _, p := range []int32{1, 5, 3} {
+		if _, err := m.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: p, Payload: []byte("p")}); err != nil {
+			t.Fatalf("Enqueue() err=%v", err)
+		}
+	}
+
+	// 5 is claimed, 3 is acked, 1 stays pending
+	res, _ := m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	claimed = res.JobId
+	res, _ = m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	if _, err := m.Ack(ctx, api.AckRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Ack() err=%v", err)
+	}
+
+	for id, job := range m.jobMap {
+		if job.Priority == 1 {
+			pending = id
+		}
+	}
+	return claimed, pending
+}
+
+func assertRestored(t *testing.T, m *Engine, claimed, pending int64) {
+	t.Helper()
+	ctx := context.Background()
+	if len(m.jobMap) != 3 {
+		t.Fatalf("restored jobs = %d, want 3", len(m.jobMap))
+	}
+
+	res, err := m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c2"})
+	if err != nil || res.JobId != pending {
+		t.Errorf("Dequeue() got = %d, want %d, err=%v", res.JobId, pending, err)
+	}
+
+	if job := m.jobMap[claimed]; job == nil || job.ClaimedBy != "c1" {
+		t.Errorf("claimed job=%d not restored got = %v", claimed, job)
+	}
+}
+
+func TestEngine_WAL_Replay(t *testing.T) {
+	dir := t.TempDir()
+	m := newDurableEngine(t, dir)
+	claimed, pending := populate(t, m)
+
+	// no Close, simulates a crash with everything only in the log
+	m = newDurableEngine(t, dir)
+	defer m.Close()
+	assertRestored(t, m, claimed, pending)
+}
+
+func TestEngine_WAL_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	m := newDurableEngine(t, dir)
+	claimed, pending := populate(t, m)
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() err=%v", err)
+	}
+
+	m = newDurableEngine(t, dir)
+	defer m.Close()
+	assertRestored(t, m, claimed, pending)
+}
+
+func TestEngine_WAL_SnapshotThenLog(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	m := newDurableEngine(t, dir)
+	claimed, pending := populate(t, m)
+	if err := m.Snapshot(); err != nil {
+		t.Fatalf("Snapshot() err=%v", err)
+	}
+
+	_, _ = m.ReQueue(ctx, api.RequeueRequest{Topic: "t1", RequeueTs: time.Now().Add(time.Second).UnixMilli()})
+
+	m = newDurableEngine(t, dir)
+	defer m.Close()
+	if job := m.jobMap[claimed]; job == nil || job.ClaimedBy != "" {
+		t.Fatalf("re-queued job=%d not restored got = %v", claimed, job)
+	}
+
+	res, _ := m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c2"})
+	if res.JobId != claimed {
+		t.Errorf("Dequeue() got = %d, want %d", res.JobId, claimed)
+	}
+	res, _ = m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c2"})
+	if res.JobId != pending {
+		t.Errorf("Dequeue() got = %d, want %d", res.JobId, pending)
+	}
+}
+
+func TestEngine_WAL_Lease(t *testing.T) {
+	dir := t.TempDir()
+	m := newDurableEngine(t, dir)
+	claimed, pending := populate(t, m)
+	ctx := context.Background()
+
+	extended, _ := m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	if _, err := m.ExtendLease(ctx, api.LeaseRequest{JobId: extended.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("ExtendLease() err=%v", err)
+	}
+	claimedAt := m.jobMap[extended.JobId].ClaimedAt
+	if _, err := m.Nack(ctx, api.LeaseRequest{JobId: claimed, Consumer: "c1"}); err != nil {
+		t.Fatalf("Nack() err=%v", err)
+	}
+
+	m = newDurableEngine(t, dir)
+	defer m.Close()
+	if extended.JobId != pending {
+		t.Fatalf("Dequeue() got = %d, want %d", extended.JobId, pending)
+	}
+	if got := m.jobMap[extended.JobId].ClaimedAt; got != claimedAt {
+		t.Errorf("extended job=%d claimed_at = %d, want %d", extended.JobId, got, claimedAt)
+	}
+	if res, _ := m.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c2"}); res.JobId != claimed {
+		t.Errorf("Dequeue() got = %d, want the nacked job %d", res.JobId, claimed)
+	}
+}
+
+func TestEngine_WAL_Purge(t *testing.T) {
+	dir := t.TempDir()
+	m := newDurableEngine(t, dir)
+	claimed, pending := populate(t, m)
+	ctx := context.Background()
+
+	if _, err := m.Purge(ctx, api.PurgeRequest{Topic: "t1", CompletedBefore: time.Now().Add(time.Minute).UnixMilli(), Archive: true}); err != api.ErrorNotSupported {
+		t.Errorf("Purge() with archive err=%v, want %v", err, api.ErrorNotSupported)
+	}
+	res, err := m.Purge(ctx, api.PurgeRequest{Topic: "t1", CompletedBefore: time.Now().Add(time.Minute).UnixMilli(), BatchSize: 10})
+	if err != nil || res.Count != 1 {
+		t.Fatalf("Purge() got = %d, err=%v, want the acked job", res.Count, err)
+	}
+
+	m = newDurableEngine(t, dir)
+	defer m.Close()
+	if len(m.jobMap) != 2 || m.jobMap[claimed] == nil || m.jobMap[pending] == nil {
+		t.Errorf("restored jobs = %v, want the claimed and pending jobs", m.jobMap)
+	}
+}
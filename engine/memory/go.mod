@@ -4,4 +4,6 @@ go 1.19
 
 require github.com/hextechpal/prio/core v0.0.0-20221125150718-3fe15c6f3658
 
-//replace github.com/hextechpal/prio/core => ../../core
+require github.com/google/uuid v1.3.0 // indirect
+
+replace github.com/hextechpal/prio/core => ../../core
@@ -16,6 +16,10 @@ func NewMaxHeap[T Comparable[T]](capacity int) *MaxHeap[T] {
 	return &MaxHeap[T]{size: 0, capacity: capacity, sl: make([]T, capacity)}
 }
 
+func (mh *MaxHeap[T]) Size() int {
+	return mh.size
+}
+
 func (mh *MaxHeap[T]) GetMax() (T, error) {
 	if mh.size == 0 {
 		var z T
@@ -30,9 +34,9 @@ func (mh *MaxHeap[T]) ExtractMax() (T, error) {
 		return z, errors.New("empty heap")
 	}
 	root := mh.sl[0]
-	mh.sl[0] = mh.sl[mh.size-1]
-	mh.shiftDown(0)
 	mh.size--
+	mh.sl[0] = mh.sl[mh.size]
+	mh.shiftDown(0)
 	return root, nil
 }
 
@@ -3,16 +3,19 @@ package models
 type Status int
 
 const (
-	PENDING Status = iota // Represents the job is just inserted in to the database
-	CLAIMED               // The job has been claimed by the consumer
+	PENDING   Status = iota // Represents the job is just inserted in to the database
+	CLAIMED                 // The job has been claimed by the consumer
+	COMPLETED               // The job has been marked completed by the consumer
 )
 
 type Job struct {
 	ID       int64
 	Topic    string
 	Payload  []byte
+	Headers  map[string]string
 	Priority int32
 	Status   Status
+	Seq      uint64 // Seq: position among the pending jobs of the same priority
 
 	ClaimedAt int64
 	ClaimedBy string
@@ -0,0 +1,188 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// headerSize : every record is framed as length (4 bytes) | crc32 of the data (4 bytes) | data
+	headerSize = 8
+
+	// maxRecordSize : larger lengths can only come from a corrupt header
+	maxRecordSize = 64 << 20
+)
+
+var errorCorrupt = errors.New("corrupt record")
+
+// Log : Append only log of opaque records
+type Log struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	w    *bufio.Writer
+	sync bool // sync: fsync after every append
+}
+
+// Open : Replays the existing records of the log at path through fn and opens it for appending.
+// A torn or corrupt tail left by a crash is truncated, everything before it is replayed
+func Open(path string, syncEveryAppend bool, fn func(data []byte) error) (*Log, error) {
+	valid, err := replay(path, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = f.Truncate(valid); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if _, err = f.Seek(valid, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Log{path: path, f: f, w: bufio.NewWriter(f), sync: syncEveryAppend}, nil
+}
+
+// Append : Writes the record to the log, the record is on disk when it returns only if the log syncs every append
+func (l *Log) Append(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var header [headerSize]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(data))
+	if _, err := l.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := l.w.Write(data); err != nil {
+		return err
+	}
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+
+	if l.sync {
+		return l.f.Sync()
+	}
+	return nil
+}
+
+// Sync : Flushes the written records to disk
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Reset : Discards all the records, called once they are captured by a snapshot
+func (l *Log) Reset() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	if err := l.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := l.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+func (l *Log) Close() error {
+	if err := l.Sync(); err != nil {
+		_ = l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// replay : Calls fn for every valid record and returns the offset after the last one
+func replay(path string, fn func(data []byte) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	valid := int64(0)
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || err == errorCorrupt {
+			return valid, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if err = fn(data); err != nil {
+			return 0, err
+		}
+		valid += int64(headerSize + len(data))
+	}
+}
+
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:4])
+	if size > maxRecordSize {
+		return nil, errorCorrupt
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, errorCorrupt
+	}
+	return data, nil
+}
+
+// WriteFile : Atomically replaces the file at path with data, the data is on disk when it returns
+func WriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
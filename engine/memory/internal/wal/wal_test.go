@@ -0,0 +1,83 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readAll(t *testing.T, path string) ([]string, *Log) {
+	t.Helper()
+	got := make([]string, 0)
+	l, err := Open(path, true, func(data []byte) error {
+		got = append(got, string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Open() err=%v", err)
+	}
+	return got, l
+}
+
+func TestLog_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	_, l := readAll(t, path)
+	for _, r := range []string{"r1", "r2", "r3"} {
+		if err := l.Append([]byte(r)); err != nil {
+			t.Fatalf("Append() err=%v", err)
+		}
+	}
+	_ = l.Close()
+
+	got, l := readAll(t, path)
+	_ = l.Close()
+	if want := []string{"r1", "r2", "r3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay got = %v, want %v", got, want)
+	}
+}
+
+func TestLog_TornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	_, l := readAll(t, path)
+	_ = l.Append([]byte("r1"))
+	_ = l.Append([]byte("r2"))
+	_ = l.Close()
+
+	// chop the last byte of r2 as if the process died mid write
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+
+	got, l := readAll(t, path)
+	if want := []string{"r1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay got = %v, want %v", got, want)
+	}
+
+	// the torn record is discarded and new records are appended after r1
+	_ = l.Append([]byte("r3"))
+	_ = l.Close()
+	got, l = readAll(t, path)
+	_ = l.Close()
+	if want := []string{"r1", "r3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay got = %v, want %v", got, want)
+	}
+}
+
+func TestLog_Reset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	_, l := readAll(t, path)
+	_ = l.Append([]byte("r1"))
+	if err := l.Reset(); err != nil {
+		t.Fatalf("Reset() err=%v", err)
+	}
+	_ = l.Append([]byte("r2"))
+	_ = l.Close()
+
+	got, l := readAll(t, path)
+	_ = l.Close()
+	if want := []string{"r2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay got = %v, want %v", got, want)
+	}
+}
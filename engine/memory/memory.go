@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
 	"github.com/hextechpal/prio/engine/memory/internal/heap"
 	"github.com/hextechpal/prio/engine/memory/internal/models"
-	"math/rand"
+	"github.com/hextechpal/prio/engine/memory/internal/wal"
 	"sync"
 	"time"
 )
@@ -20,17 +21,34 @@ type (
 		mu        sync.RWMutex
 		topicsMap map[string]*heap.MaxHeap[node]
 		jobMap    map[int64]*models.Job
+		seq       uint64 // seq: last sequence handed to a pending job, orders jobs of the same priority
+		lastId    int64  // lastId: last job id handed out, ids are sequential
+
+		walConfig *WALConfig // walConfig: durability settings, the engine is not durable if nil
+		wal       *wal.Log
+		done      chan bool // done: stops the background sync and snapshot routine
+
+		logger commons.Logger
 	}
 
+	Option = func(m *Engine)
+
 	node struct {
 		jobId    int64
 		priority int32
+		seq      uint64
 	}
 )
 
+// Compare : Higher priority is greater, for the same priority the job pending for longer (lower seq) is greater
 func (n node) Compare(x node) int {
 	if n.priority == x.priority {
-		return 0
+		if n.seq == x.seq {
+			return 0
+		} else if n.seq > x.seq {
+			return -1
+		}
+		return 1
 	} else if n.priority < x.priority {
 		return -1
 	} else {
@@ -38,21 +56,53 @@ func (n node) Compare(x node) int {
 	}
 }
 
-func NewEngine() *Engine {
-	return &Engine{
+func WithLogger(logger commons.Logger) Option {
+	return func(m *Engine) {
+		m.logger = logger
+	}
+}
+
+// NewEngine : Creates an in memory engine, with WithWAL the state is restored from the wal directory
+func NewEngine(opts ...Option) (*Engine, error) {
+	m := &Engine{
 		topicsMap: make(map[string]*heap.MaxHeap[node]),
 		jobMap:    make(map[int64]*models.Job),
+		logger:    &commons.DefaultLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
 	}
+
+	if m.walConfig != nil {
+		if err := m.open(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
 }
 
 func (m *Engine) GetTopics(_ context.Context) ([]string, error) {
-	topics := make([]string, len(m.topicsMap))
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	topics := make([]string, 0, len(m.topicsMap))
 	for tn := range m.topicsMap {
 		topics = append(topics, tn)
 	}
 	return topics, nil
 }
 
+// PendingCount : Returns the number of pending jobs of the topic, zero for an unknown topic
+func (m *Engine) PendingCount(_ context.Context, topic string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	h, ok := m.topicsMap[topic]
+	if !ok {
+		return 0, nil
+	}
+	return int64(h.Size()), nil
+}
+
 func (m *Engine) RegisterTopic(_ context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -60,6 +110,9 @@ func (m *Engine) RegisterTopic(_ context.Context, req api.RegisterTopicRequest)
 		return api.RegisterTopicResponse{}, errors.New("topic already registered")
 	}
 
+	if err := m.log(record{Op: opRegisterTopic, Topic: req.Name}); err != nil {
+		return api.RegisterTopicResponse{}, err
+	}
 	m.topicsMap[req.Name] = heap.NewMaxHeap[node](defaultCapacity)
 	return api.RegisterTopicResponse{}, nil
 }
@@ -72,26 +125,35 @@ func (m *Engine) Enqueue(_ context.Context, req api.EnqueueRequest) (api.Enqueue
 		return api.EnqueueResponse{}, errors.New("topics mot registered")
 	}
 
-	id := rand.Int63()
+	id := m.lastId + 1
 
 	job := &models.Job{
 		ID:        id,
 		Topic:     req.Topic,
 		Payload:   req.Payload,
+		Headers:   req.Headers,
 		Priority:  req.Priority,
 		Status:    models.PENDING,
+		Seq:       m.seq + 1,
 		CreatedAt: time.Now().UnixMilli(),
 		UpdatedAt: time.Now().UnixMilli(),
 	}
 
+	if err := m.log(record{Op: opEnqueue, Job: job}); err != nil {
+		return api.EnqueueResponse{}, err
+	}
+
 	err := m.topicsMap[req.Topic].Insert(node{
 		jobId:    job.ID,
 		priority: job.Priority,
+		seq:      job.Seq,
 	})
 	if err != nil {
 		return api.EnqueueResponse{}, err
 	}
 
+	m.seq = job.Seq
+	m.lastId = id
 	m.jobMap[job.ID] = job
 
 	return api.EnqueueResponse{JobId: id}, nil
@@ -105,51 +167,198 @@ func (m *Engine) Dequeue(_ context.Context, req api.DequeueRequest) (api.Dequeue
 		return api.DequeueResponse{}, errors.New("topics mot registered")
 	}
 
-	n, err := m.topicsMap[req.Topic].ExtractMax()
+	n, err := m.topicsMap[req.Topic].GetMax()
 	if err != nil {
-		return api.DequeueResponse{}, errors.New("topics mot registered")
+		// empty topic
+		return api.DequeueResponse{}, nil
 	}
-	job := m.jobMap[n.jobId]
-	job.ClaimedAt = time.Now().UnixMilli()
 
-	job.ClaimedBy = req.Consumer
-	job.Status = models.CLAIMED
-	job.UpdatedAt = time.Now().UnixMilli()
+	now := time.Now().UnixMilli()
+	if err = m.log(record{Op: opDequeue, JobIds: []int64{n.jobId}, Consumer: req.Consumer, Ts: now}); err != nil {
+		return api.DequeueResponse{}, err
+	}
+
+	_, _ = m.topicsMap[req.Topic].ExtractMax()
+	job := m.jobMap[n.jobId]
+	claim(job, req.Consumer, now)
 
 	return api.DequeueResponse{
 		JobId:    job.ID,
 		Topic:    job.Topic,
 		Payload:  job.Payload,
+		Headers:  job.Headers,
 		Priority: job.Priority,
 	}, nil
 }
 
+func (m *Engine) GetJob(_ context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobMap[req.JobId]
+	if !ok {
+		return api.GetJobResponse{}, api.ErrorJobNotPresent
+	}
+	return api.GetJobResponse{
+		JobId:     job.ID,
+		Topic:     job.Topic,
+		Payload:   job.Payload,
+		Priority:  job.Priority,
+		Headers:   job.Headers,
+		Status:    api.JobStatus(job.Status),
+		ClaimedBy: job.ClaimedBy,
+	}, nil
+}
+
 func (m *Engine) Ack(_ context.Context, req api.AckRequest) (api.AckResponse, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	_, ok := m.jobMap[req.JobId]
-	if !ok {
-		return api.AckResponse{}, api.ErrorAlreadyAcked
+	job, err := m.claimedJob(req.JobId, req.Consumer)
+	if err != nil {
+		return api.AckResponse{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	if err := m.log(record{Op: opAck, JobIds: []int64{req.JobId}, Ts: now}); err != nil {
+		return api.AckResponse{}, err
 	}
-	delete(m.jobMap, req.JobId)
+	complete(job, now)
 	return api.AckResponse{Acked: true}, nil
 }
 
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (m *Engine) ExtendLease(_ context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.claimedJob(req.JobId, req.Consumer)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	if err = m.log(record{Op: opExtend, JobIds: []int64{req.JobId}, Ts: now}); err != nil {
+		return api.LeaseResponse{}, err
+	}
+	job.ClaimedAt = now
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// Nack : Queues the job behind the pending jobs of the same priority, like an expired job
+func (m *Engine) Nack(_ context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.claimedJob(req.JobId, req.Consumer)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+
+	if err = m.log(record{Op: opRequeue, JobIds: []int64{req.JobId}}); err != nil {
+		return api.LeaseResponse{}, err
+	}
+	m.seq++
+	release(job, m.seq)
+	_ = m.topicsMap[job.Topic].Insert(node{jobId: job.ID, priority: job.Priority, seq: job.Seq})
+	return api.LeaseResponse{Ok: true}, nil
+}
+
 func (m *Engine) ReQueue(_ context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	count := int64(0)
+	expired := make([]int64, 0)
 	for _, job := range m.jobMap {
-		if job.Status == models.CLAIMED && job.ClaimedAt < req.RequeueTs {
-			job.Status = models.PENDING
-			job.ClaimedAt = 0
-			job.ClaimedBy = ""
-			_ = m.topicsMap[job.Topic].Insert(node{jobId: job.ID, priority: job.Priority})
-			count++
+		if job.Topic == req.Topic && job.Status == models.CLAIMED && job.ClaimedAt < req.RequeueTs {
+			expired = append(expired, job.ID)
 		}
 	}
 
-	return api.RequeueResponse{Count: count}, nil
+	if len(expired) == 0 {
+		return api.RequeueResponse{Count: 0}, nil
+	}
+
+	if err := m.log(record{Op: opRequeue, JobIds: expired}); err != nil {
+		return api.RequeueResponse{}, err
+	}
+
+	// expired jobs are queued behind the pending jobs of the same priority
+	for _, id := range expired {
+		job := m.jobMap[id]
+		m.seq++
+		release(job, m.seq)
+		_ = m.topicsMap[job.Topic].Insert(node{jobId: job.ID, priority: job.Priority, seq: job.Seq})
+	}
+
+	return api.RequeueResponse{Count: int64(len(expired))}, nil
+}
+
+// Purge : Deletes at most BatchSize jobs of the topic completed before CompletedBefore, the engine has no archive
+func (m *Engine) Purge(_ context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	if req.Archive {
+		return api.PurgeResponse{}, api.ErrorNotSupported
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := make([]int64, 0)
+	for _, job := range m.jobMap {
+		if req.BatchSize > 0 && len(purged) >= req.BatchSize {
+			break
+		}
+		if job.Topic == req.Topic && job.Status == models.COMPLETED && job.UpdatedAt < req.CompletedBefore {
+			purged = append(purged, job.ID)
+		}
+	}
+
+	if len(purged) == 0 {
+		return api.PurgeResponse{Count: 0}, nil
+	}
+
+	if err := m.log(record{Op: opPurge, JobIds: purged}); err != nil {
+		return api.PurgeResponse{}, err
+	}
+	for _, id := range purged {
+		delete(m.jobMap, id)
+	}
+	return api.PurgeResponse{Count: int64(len(purged))}, nil
+}
+
+// claimedJob : Returns the job if it is claimed by the consumer, the error tells why otherwise
+func (m *Engine) claimedJob(id int64, consumer string) (*models.Job, error) {
+	job, ok := m.jobMap[id]
+	if !ok {
+		return nil, api.ErrorJobNotPresent
+	}
+
+	switch {
+	case job.Status == models.COMPLETED:
+		return nil, api.ErrorAlreadyAcked
+	case job.Status == models.PENDING:
+		return nil, api.ErrorLeaseExceeded
+	case job.ClaimedBy != consumer:
+		return nil, api.ErrorWrongConsumer
+	}
+	return job, nil
+}
+
+func claim(job *models.Job, consumer string, ts int64) {
+	job.ClaimedAt = ts
+	job.ClaimedBy = consumer
+	job.Status = models.CLAIMED
+	job.UpdatedAt = ts
+}
+
+func release(job *models.Job, seq uint64) {
+	job.Status = models.PENDING
+	job.Seq = seq
+	job.ClaimedAt = 0
+	job.ClaimedBy = ""
+}
+
+func complete(job *models.Job, ts int64) {
+	job.Status = models.COMPLETED
+	job.UpdatedAt = ts
 }
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+)
+
+func TestEngine_Conformance(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		m, err := NewEngine()
+		if err != nil {
+			t.Fatalf("NewEngine() err=%v", err)
+		}
+		return m
+	})
+}
+
+func TestEngine_Conformance_WAL(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		m, err := NewEngine(WithWAL(WALConfig{Dir: t.TempDir()}))
+		if err != nil {
+			t.Fatalf("NewEngine() err=%v", err)
+		}
+		t.Cleanup(func() { _ = m.Close() })
+		return m
+	})
+}
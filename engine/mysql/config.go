@@ -1,15 +1,146 @@
 package mysql
 
-import "fmt"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
 
-type Config struct {
-	Host     string
-	Port     int32
-	User     string
-	Password string
-	DBName   string
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type (
+	Config struct {
+		Host     string
+		Port     int32
+		User     string
+		Password string
+		DBName   string
+
+		Timeout      time.Duration // Timeout: dial timeout
+		ReadTimeout  time.Duration // ReadTimeout: I/O read timeout
+		WriteTimeout time.Duration // WriteTimeout: I/O write timeout
+		ParseTime    bool          // ParseTime: scan DATE and DATETIME columns into time.Time
+
+		Pool PoolConfig
+		TLS  TLSConfig
+
+		Replicas []string // Replicas: host:port of the read replicas serving the topic listing and the pending counts, they share the credentials and settings of the primary
+	}
+
+	// PoolConfig : Connection pool settings, zero values keep the database/sql defaults
+	PoolConfig struct {
+		MaxOpenConns    int
+		MaxIdleConns    int
+		ConnMaxLifetime time.Duration
+		ConnMaxIdleTime time.Duration
+	}
+
+	// TLSConfig : TLS settings of the connection
+	TLSConfig struct {
+		Mode       string // Mode: true, false, skip-verify or preferred, only true and skip-verify apply to custom certificates
+		CACert     string // CACert: path of the PEM encoded CA certificate used to verify the server
+		ClientCert string // ClientCert: path of the PEM encoded client certificate
+		ClientKey  string // ClientKey: path of the PEM encoded client key
+		ServerName string // ServerName: overrides the host name used to verify the server certificate
+	}
+)
+
+func (c Config) dsn() (string, error) {
+	return c.dsnFor(fmt.Sprintf("%s:%d", c.Host, c.Port))
+}
+
+// dsnFor : Returns the dsn connecting to the server at addr with the settings of the config
+func (c Config) dsnFor(addr string) (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = addr
+	cfg.DBName = c.DBName
+	cfg.Timeout = c.Timeout
+	cfg.ReadTimeout = c.ReadTimeout
+	cfg.WriteTimeout = c.WriteTimeout
+	cfg.ParseTime = c.ParseTime
+
+	tlsConfig, err := c.TLS.name(addr)
+	if err != nil {
+		return "", err
+	}
+	cfg.TLSConfig = tlsConfig
+	return cfg.FormatDSN(), nil
+}
+
+func (p PoolConfig) apply(db *sqlx.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// name : Returns the tls parameter of the dsn, the certificates are registered with the driver as a custom config
+// under a name of its own for every server and settings, the driver registry being shared by the whole process
+func (t TLSConfig) name(addr string) (string, error) {
+	if t.CACert == "" && t.ClientCert == "" && t.ServerName == "" {
+		return t.Mode, nil
+	}
+
+	switch t.Mode {
+	case "", "true", "skip-verify":
+	default:
+		return "", fmt.Errorf("tls mode %s can not be used with certificates", t.Mode)
+	}
+
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.Mode == "skip-verify",
+	}
+
+	if t.CACert != "" {
+		pem, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return "", err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return "", errors.New("no certificate found in " + t.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return "", err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	name := t.registeredName(addr)
+	if err := mysql.RegisterTLSConfig(name, cfg); err != nil {
+		return "", err
+	}
+	return name, nil
 }
 
-func (c Config) dsn() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", c.User, c.Password, c.Host, c.Port, c.DBName)
+// registeredName : Name of the custom tls config of the server, derived from the address and the tls settings
+func (t TLSConfig) registeredName(addr string) string {
+	h := fnv.New64a()
+	for _, v := range []string{addr, t.Mode, t.CACert, t.ClientCert, t.ClientKey, t.ServerName} {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	return fmt.Sprintf("prio-%x", h.Sum64())
 }
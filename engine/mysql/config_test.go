@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_dsn(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Defaults",
+			config: Config{Host: "localhost", Port: 3306, User: "root", Password: "root", DBName: "prio"},
+			want:   "root:root@tcp(localhost:3306)/prio",
+		},
+		{
+			name: "Timeouts and parse time",
+			config: Config{
+				Host: "localhost", Port: 3306, User: "root", Password: "root", DBName: "prio",
+				Timeout: time.Second, ReadTimeout: 2 * time.Second, WriteTimeout: 3 * time.Second, ParseTime: true,
+			},
+			want: "root:root@tcp(localhost:3306)/prio?parseTime=true&readTimeout=2s&timeout=1s&writeTimeout=3s",
+		},
+		{
+			name:   "TLS mode",
+			config: Config{Host: "localhost", Port: 3306, User: "root", DBName: "prio", TLS: TLSConfig{Mode: "skip-verify"}},
+			want:   "root@tcp(localhost:3306)/prio?tls=skip-verify",
+		},
+		{
+			name:   "TLS server name",
+			config: Config{Host: "localhost", Port: 3306, User: "root", DBName: "prio", TLS: TLSConfig{ServerName: "db.internal"}},
+			want:   "root@tcp(localhost:3306)/prio?tls=" + TLSConfig{ServerName: "db.internal"}.registeredName("localhost:3306"),
+		},
+		{
+			name:    "Missing CA certificate",
+			config:  Config{Host: "localhost", Port: 3306, User: "root", DBName: "prio", TLS: TLSConfig{CACert: "does-not-exist.pem"}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid TLS mode with certificates",
+			config:  Config{Host: "localhost", Port: 3306, User: "root", DBName: "prio", TLS: TLSConfig{Mode: "false", ServerName: "db.internal"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.dsn()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dsn() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("dsn() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_registeredName(t *testing.T) {
+	primary := TLSConfig{ServerName: "db.internal"}
+	if primary.registeredName("db1:3306") != primary.registeredName("db1:3306") {
+		t.Errorf("registeredName() should be stable for the same server and settings")
+	}
+	if primary.registeredName("db1:3306") == primary.registeredName("db2:3306") {
+		t.Errorf("registeredName() should differ across servers")
+	}
+	if primary.registeredName("db1:3306") == (TLSConfig{ServerName: "other.internal"}).registeredName("db1:3306") {
+		t.Errorf("registeredName() should differ across tls settings")
+	}
+}
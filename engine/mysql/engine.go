@@ -3,49 +3,114 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/hextechpal/prio/core/api"
 	"github.com/hextechpal/prio/core/commons"
 	"github.com/hextechpal/prio/engine/mysql/internal/models"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
+// mysql error numbers of the failures which may go away on retry
 const (
-	allTopics = `SELECT topics.name from topics`
-	addTopic  = `INSERT INTO topics(name, description, created_at, updated_at) VALUES (?, ?, ?, ?)`
+	erLockWaitTimeout = 1205
+	erDeadlock        = 1213
+)
+
+const (
+	allTopics   = `SELECT topics.name from topics`
+	pendingJobs = `SELECT COUNT(*) from jobs where jobs.topic = ? AND jobs.status = ?`
+	addTopic    = `INSERT INTO topics(name, description, created_at, updated_at) VALUES (?, ?, ?, ?)`
 
-	addJob = `INSERT INTO jobs(topic, payload, priority, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	addJob = `INSERT INTO jobs(topic, payload, headers, priority, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	topJob   = `SELECT jobs.id, jobs.payload, jobs.priority from jobs where jobs.topic = ? AND jobs.status = ? ORDER BY priority DESC, updated_at LIMIT 1 FOR UPDATE`
-	claimJob = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?  WHERE jobs.id = ?`
+	// topJob locks the top priority pending job skipping the rows locked by concurrent consumers (requires MySQL 8)
+	topJob   = `SELECT jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority from jobs where jobs.topic = ? AND jobs.status = ? ORDER BY priority DESC, updated_at, id LIMIT 1 FOR UPDATE SKIP LOCKED`
+	claimJob = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ? WHERE jobs.id = ? AND jobs.status = ?`
 
-	jobById     = `SELECT jobs.id, jobs.status from jobs where jobs.id = ? FOR UPDATE `
+	jobById     = `SELECT jobs.id, jobs.status, jobs.claimed_by from jobs where jobs.id = ? FOR UPDATE`
+	getJob      = `SELECT jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority, jobs.status, jobs.claimed_by from jobs where jobs.id = ?`
 	completeJob = `UPDATE jobs SET status = ?, completed_at = ? WHERE jobs.id = ?`
+	extendLease = `UPDATE jobs SET claimed_at = ? WHERE jobs.id = ?`
+	nackJob     = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?, updated_at = ? WHERE jobs.id = ?`
 
 	reQueue = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?, updated_at = ? WHERE jobs.topic = ? AND jobs.status = ? AND jobs.claimed_at < ?`
 )
 
-type Engine struct {
-	*sqlx.DB
-	config Config
-	logger commons.Logger
+type (
+	Engine struct {
+		*sqlx.DB
+		config   Config
+		logger   commons.Logger
+		replicas *replicas // replicas: read replicas serving the read only operations
+
+		schemaCheck bool // schemaCheck: refuse to start if the applied migrations differ from the embedded ones
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+// WithSchemaCheck : Makes NewEngine fail with ErrorSchemaMismatch unless all the embedded migrations, and only those, are applied
+func WithSchemaCheck() Option {
+	return func(s *Engine) {
+		s.schemaCheck = true
+	}
 }
 
-func NewEngine(config Config) (*Engine, error) {
-	db, err := sqlx.Connect("mysql", config.dsn())
+func NewEngine(config Config, opts ...Option) (*Engine, error) {
+	dsn, err := config.dsn()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sqlx.Connect("mysql", dsn)
 	if err != nil {
 		return nil, err
 	}
+	config.Pool.apply(db)
+
+	r, err := openReplicas(config)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
 	s := &Engine{
-		DB:     db,
-		config: config,
+		DB:       db,
+		config:   config,
+		logger:   &commons.DefaultLogger{},
+		replicas: r,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.schemaCheck {
+		if err = s.checkSchema(context.Background()); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
+// Close : Closes the replicas and the primary
+func (s *Engine) Close() error {
+	if err := s.replicas.close(); err != nil {
+		s.logger.Error(err, "error closing replicas")
+	}
+	return s.DB.Close()
+}
+
 func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
 	_, err := s.ExecContext(ctx, addTopic, req.Name, req.Description, time.Now().UnixMilli(), time.Now().UnixMilli())
 	if err != nil {
@@ -56,7 +121,7 @@ func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest
 }
 
 func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
-	r, err := s.ExecContext(ctx, addJob, req.Topic, req.Payload, req.Priority, models.PENDING, time.Now().UnixMilli(), time.Now().UnixMilli())
+	r, err := s.ExecContext(ctx, addJob, req.Topic, req.Payload, commons.Headers(req.Headers), req.Priority, models.PENDING, time.Now().UnixMilli(), time.Now().UnixMilli())
 	if err != nil {
 		return api.EnqueueResponse{}, err
 	}
@@ -66,19 +131,20 @@ func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.Enque
 }
 
 func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
-	// Find Top priority item with status pending
-	// Update the status to delivered and delivered_at timestamp to NOW()
-	// return the updated object
-
-	tx := s.MustBeginTx(ctx, &sql.TxOptions{})
+	// Lock the top priority pending job inside the transaction, rows locked by other consumers are skipped
+	// so that concurrent consumers on a topic claim different jobs instead of waiting on each other
+	tx, err := s.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return api.DequeueResponse{}, err
+	}
 	defer func() {
-		if err := tx.Rollback(); err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
 			s.logger.Error(err, "error while dequeue")
 		}
 	}()
 
 	var job models.Job
-	err := s.GetContext(ctx, &job, topJob, req.Topic, models.PENDING)
+	err = tx.GetContext(ctx, &job, topJob, req.Topic, models.PENDING)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return api.DequeueResponse{}, nil
@@ -86,7 +152,7 @@ func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.Deque
 		return api.DequeueResponse{}, err
 	}
 
-	result, err := tx.ExecContext(ctx, claimJob, models.CLAIMED, time.Now().UnixMilli(), req.Consumer, job.ID)
+	result, err := tx.ExecContext(ctx, claimJob, models.CLAIMED, time.Now().UnixMilli(), req.Consumer, job.ID, models.PENDING)
 	if err != nil {
 		return api.DequeueResponse{}, err
 	}
@@ -103,53 +169,106 @@ func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.Deque
 		Topic:    job.Topic,
 		Payload:  job.Payload,
 		Priority: job.Priority,
+		Headers:  job.Headers,
+	}, nil
+}
+
+// GetJob : Returns the job from the primary, callers check the status and the claim of the job
+// which must not lag behind like a replica does
+func (s *Engine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	var job models.Job
+	if err := s.GetContext(ctx, &job, getJob, req.JobId); err != nil {
+		if err == sql.ErrNoRows {
+			return api.GetJobResponse{}, api.ErrorJobNotPresent
+		}
+		return api.GetJobResponse{}, err
+	}
+	return api.GetJobResponse{
+		JobId:     job.ID,
+		Topic:     job.Topic,
+		Payload:   job.Payload,
+		Priority:  job.Priority,
+		Headers:   job.Headers,
+		Status:    api.JobStatus(job.Status),
+		ClaimedBy: job.ClaimedBy.String,
 	}, nil
 }
 
 func (s *Engine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
-	tx := s.MustBeginTx(ctx, &sql.TxOptions{})
+	err := s.lease(ctx, req.JobId, req.Consumer, "ack", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, completeJob, models.COMPLETED, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.AckResponse{Acked: false}, err
+	}
+	return api.AckResponse{Acked: true}, nil
+}
+
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (s *Engine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.lease(ctx, req.JobId, req.Consumer, "extend lease", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, extendLease, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// Nack : Moves the job back to pending, it is queued behind the pending jobs of the same priority
+func (s *Engine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.lease(ctx, req.JobId, req.Consumer, "nack", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, nackJob, models.PENDING, nil, nil, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// lease : Locks the job, checks it is claimed by the consumer and runs update in the same transaction
+func (s *Engine) lease(ctx context.Context, id int64, consumer string, op string, update func(tx *sqlx.Tx) (sql.Result, error)) error {
+	tx, err := s.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
 	defer func() {
-		if err := tx.Rollback(); err != nil {
-			s.logger.Error(err, "error during ack")
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Error(err, "error during "+op)
 		}
 	}()
 
 	var job models.Job
-	err := s.GetContext(ctx, &job, jobById, req.JobId)
-	errRes := api.AckResponse{Acked: false}
+	err = tx.GetContext(ctx, &job, jobById, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return errRes, api.ErrorJobNotPresent
+			return api.ErrorJobNotPresent
 		}
-		return errRes, err
+		return err
 	}
 
 	if job.Status == models.COMPLETED {
-		return errRes, api.ErrorAlreadyAcked
+		return api.ErrorAlreadyAcked
 	}
 
 	if job.Status == models.PENDING {
-		return errRes, api.ErrorLeaseExceeded
+		return api.ErrorLeaseExceeded
 	}
 
-	if job.Status == models.CLAIMED && job.ClaimedBy.String != req.Consumer {
-		return errRes, api.ErrorWrongConsumer
+	if job.Status == models.CLAIMED && job.ClaimedBy.String != consumer {
+		return api.ErrorWrongConsumer
 	}
 
-	result, err := tx.ExecContext(ctx, completeJob, models.CLAIMED, time.Now().UnixMilli(), job.ID)
+	result, err := update(tx)
 	if err != nil {
-		return errRes, err
+		return err
 	}
 
 	if affected, _ := result.RowsAffected(); affected == 0 {
-		return errRes, api.ErrorGeneral
+		return api.ErrorGeneral
 	}
 
-	if err = tx.Commit(); err != nil {
-		return errRes, err
-	}
-
-	return api.AckResponse{Acked: true}, nil
+	return tx.Commit()
 }
 
 func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
@@ -163,9 +282,31 @@ func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.Reque
 
 func (s *Engine) GetTopics(ctx context.Context) ([]string, error) {
 	var topics []string
-	err := s.SelectContext(ctx, &topics, allTopics)
+	err := s.read(ctx, func(db *sqlx.DB) error {
+		topics = topics[:0]
+		return db.SelectContext(ctx, &topics, allTopics)
+	})
 	if err != nil {
 		return []string{}, err
 	}
 	return topics, nil
 }
+
+// PendingCount : Returns the number of pending jobs of the topic, served by a replica if there is one
+func (s *Engine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	var count int64
+	err := s.read(ctx, func(db *sqlx.DB) error {
+		return db.GetContext(ctx, &count, pendingJobs, topic, models.PENDING)
+	})
+	return count, err
+}
+
+// IsTransient : Reports whether the error may go away on retry, a deadlock, a lock wait timeout, an invalid connection
+// or one of the connection failures of api.IsTransient. It is the Retryable of api.Retry for this engine
+func IsTransient(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == erDeadlock || mysqlErr.Number == erLockWaitTimeout
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || api.IsTransient(err)
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+)
+
+// newTestEngine : Connects to the database configured by PRIO_TEST_MYSQL_* env and re-creates the schema.
+// The tests are skipped if PRIO_TEST_MYSQL_HOST is not set
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	host := os.Getenv("PRIO_TEST_MYSQL_HOST")
+	if host == "" {
+		t.Skip("PRIO_TEST_MYSQL_HOST not set, skipping mysql tests")
+	}
+
+	port, _ := strconv.Atoi(os.Getenv("PRIO_TEST_MYSQL_PORT"))
+	if port == 0 {
+		port = 3306
+	}
+	s, err := NewEngine(Config{
+		Host:     host,
+		Port:     int32(port),
+		User:     os.Getenv("PRIO_TEST_MYSQL_USER"),
+		Password: os.Getenv("PRIO_TEST_MYSQL_PASSWORD"),
+		DBName:   os.Getenv("PRIO_TEST_MYSQL_DATABASE"),
+	})
+	if err != nil {
+		t.Fatalf("error connecting to mysql err=%v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	for _, table := range []string{"prio_schema_migrations", "jobs_archive", "jobs", "topics"} {
+		if _, err = s.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			t.Fatalf("error dropping table=%s err=%v", table, err)
+		}
+	}
+	if err = s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() err=%v", err)
+	}
+	return s
+}
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		return newTestEngine(t)
+	})
+}
+
+func TestEngine_Purge(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive bool
+	}{
+		{name: "Delete", archive: false},
+		{name: "Archive", archive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestEngine(t)
+			ctx := context.Background()
+			_, _ = s.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+
+			for i := 0; i < 3; i++ {
+				_, _ = s.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+			}
+			for i := 0; i < 2; i++ {
+				res, _ := s.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+				if _, err := s.Ack(ctx, api.AckRequest{JobId: res.JobId, Consumer: "c1"}); err != nil {
+					t.Fatalf("Ack() err=%v", err)
+				}
+			}
+
+			req := api.PurgeRequest{Topic: "t1", CompletedBefore: time.Now().Add(time.Second).UnixMilli(), Archive: tt.archive, BatchSize: 1}
+			for _, want := range []int64{1, 1, 0} {
+				res, err := s.Purge(ctx, req)
+				if err != nil || res.Count != want {
+					t.Fatalf("Purge() count=%d, want %d, err=%v", res.Count, want, err)
+				}
+			}
+
+			var remaining, archived int
+			_ = s.Get(&remaining, `SELECT COUNT(*) from jobs`)
+			_ = s.Get(&archived, `SELECT COUNT(*) from jobs_archive`)
+			wantArchived := 0
+			if tt.archive {
+				wantArchived = 2
+			}
+			if remaining != 1 || archived != wantArchived {
+				t.Errorf("Purge() left jobs=%d archived=%d, want jobs=1 archived=%d", remaining, archived, wantArchived)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "deadlock", err: &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, want: true},
+		{name: "lock wait timeout", err: fmt.Errorf("ack: %w", &mysql.MySQLError{Number: 1205}), want: true},
+		{name: "invalid connection", err: mysql.ErrInvalidConn, want: true},
+		{name: "duplicate entry", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}},
+		{name: "foreign key", err: &mysql.MySQLError{Number: 1452}},
+		{name: "contract error", err: api.ErrorWrongConsumer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
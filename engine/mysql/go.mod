@@ -10,4 +10,4 @@ require (
 
 require github.com/google/uuid v1.3.0 // indirect
 
-//replace github.com/hextechpal/prio/core => ../../core
+replace github.com/hextechpal/prio/core => ../../core
@@ -1,6 +1,10 @@
 package models
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/hextechpal/prio/core/commons"
+)
 
 type Status int
 
@@ -11,11 +15,12 @@ const (
 )
 
 type Job struct {
-	ID       int64  `db:"id"`
-	Topic    string `db:"topic"`
-	Payload  []byte `db:"payload"`
-	Priority int32  `db:"priority"`
-	Status   Status `db:"status"`
+	ID       int64           `db:"id"`
+	Topic    string          `db:"topic"`
+	Payload  []byte          `db:"payload"`
+	Headers  commons.Headers `db:"headers"`
+	Priority int32           `db:"priority"`
+	Status   Status          `db:"status"`
 
 	ClaimedAt int64          `db:"claimed_at"`
 	ClaimedBy sql.NullString `db:"claimed_by"`
@@ -0,0 +1,311 @@
+package mysql
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrations are tracked in a table of their own so that they do not clash with the schema_migrations table
+// of golang-migrate and the like in a database shared with other applications
+const (
+	createMigrationsTable = `CREATE TABLE IF NOT EXISTS prio_schema_migrations (
+		version BIGINT primary key NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`
+	appliedMigrations = `SELECT version, name, applied_at from prio_schema_migrations ORDER BY version`
+	addMigration      = `INSERT INTO prio_schema_migrations(version, name, applied_at) VALUES (?, ?, ?)`
+	removeMigration   = `DELETE FROM prio_schema_migrations WHERE version = ?`
+	tableExists       = `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?`
+)
+
+// handAppliedTables : Tables created by the migrations which were applied by hand before the migration runner,
+// a database holding them without recorded migrations is adopted instead of migrated from scratch
+var handAppliedTables = map[int64]string{
+	1: "topics",
+	2: "jobs",
+}
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var ErrorSchemaMismatch = errors.New("database schema does not match the engine, run the migrations")
+
+type (
+	migration struct {
+		version int64
+		name    string
+		up      string
+		down    string
+	}
+
+	// MigrationStatus : State of a single migration shipped with the engine
+	MigrationStatus struct {
+		Version   int64
+		Name      string
+		Applied   bool
+		AppliedAt int64 // AppliedAt: timestamp(ms) at which the migration was applied, zero if pending
+	}
+)
+
+// Migrate : Applies all the pending migrations in order and records them in the prio_schema_migrations table.
+// Every migration is a single statement so that a failure leaves nothing half applied
+func (s *Engine) Migrate(ctx context.Context) error {
+	all, applied, err := s.migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		if applied, err = s.adopt(ctx, all); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range all {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		if err = s.execScript(ctx, m.up); err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", m.version, m.name, err)
+		}
+		if _, err = s.ExecContext(ctx, addMigration, m.version, m.name, time.Now().UnixMilli()); err != nil {
+			return err
+		}
+		s.logger.Info("applied migration version=%d name=%s", m.version, m.name)
+	}
+	return nil
+}
+
+// Rollback : Reverts the last steps applied migrations
+func (s *Engine) Rollback(ctx context.Context, steps int) error {
+	all, applied, err := s.migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+		m := all[i]
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+		if err = s.execScript(ctx, m.down); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", m.version, m.name, err)
+		}
+		if _, err = s.ExecContext(ctx, removeMigration, m.version); err != nil {
+			return err
+		}
+		s.logger.Info("reverted migration version=%d name=%s", m.version, m.name)
+		steps--
+	}
+	return nil
+}
+
+// adopt : Records the hand applied migrations whose tables exist without running them, in order and up to the first missing table
+func (s *Engine) adopt(ctx context.Context, all []migration) (map[int64]int64, error) {
+	applied := make(map[int64]int64)
+	for _, m := range all {
+		table, ok := handAppliedTables[m.version]
+		if !ok {
+			break
+		}
+
+		var count int
+		if err := s.GetContext(ctx, &count, tableExists, table); err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			break
+		}
+
+		now := time.Now().UnixMilli()
+		if _, err := s.ExecContext(ctx, addMigration, m.version, m.name, now); err != nil {
+			return nil, err
+		}
+		applied[m.version] = now
+		s.logger.Info("adopted hand applied migration version=%d name=%s", m.version, m.name)
+	}
+	return applied, nil
+}
+
+// MigrationStatus : Lists the migrations shipped with the engine and whether they are applied
+func (s *Engine) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	all, applied, err := s.migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		appliedAt, ok := applied[m.version]
+		statuses = append(statuses, MigrationStatus{Version: m.version, Name: m.name, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// checkSchema : Fails with ErrorSchemaMismatch if the applied migrations differ from the ones shipped with the engine
+func (s *Engine) checkSchema(ctx context.Context) error {
+	all, applied, err := s.migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[int64]bool, len(all))
+	for _, m := range all {
+		known[m.version] = true
+		if _, ok := applied[m.version]; !ok {
+			return fmt.Errorf("%w: version=%d name=%s is pending", ErrorSchemaMismatch, m.version, m.name)
+		}
+	}
+
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: version=%d is unknown to the engine", ErrorSchemaMismatch, version)
+		}
+	}
+	return nil
+}
+
+// migrations : Returns the embedded migrations ordered by version and the applied versions with their timestamp
+func (s *Engine) migrations(ctx context.Context) ([]migration, map[int64]int64, error) {
+	all, err := loadMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err = s.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, nil, err
+	}
+
+	var rows []struct {
+		Version   int64  `db:"version"`
+		Name      string `db:"name"`
+		AppliedAt int64  `db:"applied_at"`
+	}
+	if err = s.SelectContext(ctx, &rows, appliedMigrations); err != nil {
+		return nil, nil, err
+	}
+
+	applied := make(map[int64]int64, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return all, applied, nil
+}
+
+// execScript : Runs the statements of a migration file one by one as the driver does not allow multiple statements.
+// The error names the failing statement, the ones before it are committed as mysql does not roll back ddl
+func (s *Engine) execScript(ctx context.Context, script string) error {
+	for i, stmt := range splitStatements(script) {
+		if _, err := s.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements : Splits a script on the semicolons ending the statements, the ones inside quotes,
+// identifiers and comments are kept. Empty statements are dropped
+func splitStatements(script string) []string {
+	var stmts []string
+	var quote byte // quote: the quote, backtick or comment start the scanner is in, zero outside
+	start := 0
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote == '-' || quote == '#':
+			if c == '\n' {
+				quote = 0
+			}
+		case quote == '*':
+			if c == '*' && i+1 < len(script) && script[i+1] == '/' {
+				quote = 0
+				i++
+			}
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '#':
+			quote = c
+		case c == '-' && strings.HasPrefix(script[i:], "-- "):
+			quote = '-'
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			quote = '*'
+			i++
+		case c == ';':
+			stmts = append(stmts, script[start:i])
+			start = i + 1
+		}
+	}
+	stmts = append(stmts, script[start:])
+
+	nonEmpty := stmts[:0]
+	for _, stmt := range stmts {
+		if strings.TrimSpace(stmt) != "" {
+			nonEmpty = append(nonEmpty, stmt)
+		}
+	}
+	return nonEmpty
+}
+
+// loadMigrations : Parses the embedded files named <version>_<name>.<up|down>.sql
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, f := range files {
+		base := strings.TrimSuffix(path.Base(f), ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", f)
+		}
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", f, err)
+		}
+
+		data, err := migrationFiles.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: parts[1]}
+			byVersion[version] = m
+		}
+
+		switch direction {
+		case ".up":
+			m.up = string(data)
+		case ".down":
+			m.down = string(data)
+		default:
+			return nil, fmt.Errorf("invalid migration direction %s", f)
+		}
+	}
+
+	all := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		all = append(all, *m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].version < all[j].version })
+	return all, nil
+}
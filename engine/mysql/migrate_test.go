@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_loadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations() err=%v", err)
+	}
+
+	if len(migrations) == 0 {
+		t.Fatalf("loadMigrations() got no migrations")
+	}
+
+	for i, m := range migrations {
+		if m.version != int64(i+1) {
+			t.Errorf("loadMigrations() got version=%d at %d, want %d", m.version, i, i+1)
+		}
+		if m.name == "" || m.up == "" || m.down == "" {
+			t.Errorf("loadMigrations() version=%d is incomplete name=%q", m.version, m.name)
+		}
+		// a failing statement of a multi statement migration would leave the ones before it applied and unrecorded
+		if len(splitStatements(m.up)) != 1 || len(splitStatements(m.down)) != 1 {
+			t.Errorf("loadMigrations() version=%d should have a single up and down statement", m.version)
+		}
+	}
+}
+
+func TestEngine_Migrate_HandApplied(t *testing.T) {
+	s := newTestEngine(t)
+	ctx := context.Background()
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations() err=%v", err)
+	}
+
+	// the schema of a database set up by hand from the sql files shipped before the migration runner
+	for _, table := range []string{"prio_schema_migrations", "jobs_archive", "jobs", "topics"} {
+		_, _ = s.Exec("DROP TABLE IF EXISTS " + table)
+	}
+	for _, m := range all[:len(handAppliedTables)] {
+		if _, err = s.Exec(splitStatements(m.up)[0]); err != nil {
+			t.Fatalf("hand applying version=%d err=%v", m.version, err)
+		}
+	}
+	if _, err = s.Exec("INSERT INTO topics(name) VALUES ('t1')"); err != nil {
+		t.Fatalf("Exec() err=%v", err)
+	}
+
+	if err = s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() err=%v", err)
+	}
+	if err = s.checkSchema(ctx); err != nil {
+		t.Errorf("checkSchema() err=%v", err)
+	}
+	if topics, err := s.GetTopics(ctx); err != nil || len(topics) != 1 {
+		t.Errorf("GetTopics() got = %v, err=%v, want the hand registered topic", topics, err)
+	}
+}
+
+func Test_splitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "Statements", script: "CREATE TABLE a (id INT);\nCREATE INDEX i ON a(id);\n", want: []string{"CREATE TABLE a (id INT)", "\nCREATE INDEX i ON a(id)"}},
+		{name: "Quoted semicolons", script: "INSERT INTO a VALUES ('x;y', \"z;\", 'it\\'s;');SELECT `a;b` FROM a", want: []string{"INSERT INTO a VALUES ('x;y', \"z;\", 'it\\'s;')", "SELECT `a;b` FROM a"}},
+		{name: "Comments", script: "-- drop; nothing\nSELECT 1; /* a; b */ SELECT 2; # c;\n", want: []string{"-- drop; nothing\nSELECT 1", " /* a; b */ SELECT 2", " # c;\n"}},
+		{name: "Empty", script: " ;\n; ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitStatements(tt.script)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitStatements() got = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("splitStatements() statement %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_Migrate(t *testing.T) {
+	s := newTestEngine(t)
+	ctx := context.Background()
+
+	if err := s.checkSchema(ctx); err != nil {
+		t.Fatalf("checkSchema() after Migrate() err=%v", err)
+	}
+
+	if err := s.Rollback(ctx, 1); err != nil {
+		t.Fatalf("Rollback() err=%v", err)
+	}
+
+	statuses, err := s.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus() err=%v", err)
+	}
+	for i, st := range statuses {
+		if want := i < len(statuses)-1; st.Applied != want {
+			t.Errorf("MigrationStatus() version=%d applied=%v, want %v", st.Version, st.Applied, want)
+		}
+	}
+
+	if err = s.checkSchema(ctx); !errors.Is(err, ErrorSchemaMismatch) {
+		t.Errorf("checkSchema() after Rollback() err=%v, want %v", err, ErrorSchemaMismatch)
+	}
+
+	if err = s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() err=%v", err)
+	}
+	if err = s.checkSchema(ctx); err != nil {
+		t.Errorf("checkSchema() err=%v", err)
+	}
+}
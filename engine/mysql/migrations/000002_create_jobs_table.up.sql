@@ -13,7 +13,6 @@ CREATE TABLE jobs (
     created_at BIGINT DEFAULT NULL,
     updated_at BIGINT DEFAULT NULL,
 
-    FOREIGN KEY (topic) REFERENCES topics(name)
-);
-
-CREATE INDEX top_job_idx ON jobs(topic, status, priority DESC, updated_at ASC);
\ No newline at end of file
+    FOREIGN KEY (topic) REFERENCES topics(name),
+    INDEX top_job_idx (topic, status, priority DESC, updated_at ASC)
+);
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE jobs_archive (
+    id BIGINT primary key NOT NULL,
+    topic VARCHAR(20) NOT NULL,
+    payload BLOB,
+    priority INT NOT NULL,
+    status TINYINT NOT NULL,
+
+    claimed_at BIGINT DEFAULT NULL,
+    claimed_by VARCHAR(50) DEFAULT NULL,
+
+    completed_at BIGINT DEFAULT NULL,
+
+    created_at BIGINT DEFAULT NULL,
+    updated_at BIGINT DEFAULT NULL,
+    archived_at BIGINT DEFAULT NULL,
+
+    INDEX archive_topic_idx (topic, completed_at)
+);
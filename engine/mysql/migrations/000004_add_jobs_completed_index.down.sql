@@ -0,0 +1 @@
+DROP INDEX completed_job_idx ON jobs;
@@ -0,0 +1 @@
+CREATE INDEX completed_job_idx ON jobs(topic, status, completed_at);
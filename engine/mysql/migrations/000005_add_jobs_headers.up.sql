@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN headers JSON DEFAULT NULL AFTER payload;
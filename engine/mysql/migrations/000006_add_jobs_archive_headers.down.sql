@@ -0,0 +1 @@
+ALTER TABLE jobs_archive DROP COLUMN headers;
@@ -0,0 +1 @@
+ALTER TABLE jobs_archive ADD COLUMN headers JSON DEFAULT NULL AFTER payload;
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/engine/mysql/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	completedJobs = `SELECT jobs.id from jobs where jobs.topic = ? AND jobs.status = ? AND jobs.completed_at < ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED`
+
+	archiveJobs = `INSERT INTO jobs_archive(id, topic, payload, headers, priority, status, claimed_at, claimed_by, completed_at, created_at, updated_at, archived_at)
+		SELECT id, topic, payload, headers, priority, status, claimed_at, claimed_by, completed_at, created_at, updated_at, ? from jobs where jobs.id IN (?)`
+	deleteJobs = `DELETE from jobs where jobs.id IN (?)`
+)
+
+// Purge : Archives or deletes a batch of the jobs of a topic completed before the given timestamp
+func (s *Engine) Purge(ctx context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	tx, err := s.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return api.PurgeResponse{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Error(err, "error during purge")
+		}
+	}()
+
+	var ids []int64
+	err = tx.SelectContext(ctx, &ids, completedJobs, req.Topic, models.COMPLETED, req.CompletedBefore, req.BatchSize)
+	if err != nil {
+		return api.PurgeResponse{}, err
+	}
+
+	if len(ids) == 0 {
+		return api.PurgeResponse{}, nil
+	}
+
+	if req.Archive {
+		if err = execIn(ctx, tx, archiveJobs, time.Now().UnixMilli(), ids); err != nil {
+			return api.PurgeResponse{}, err
+		}
+	}
+
+	if err = execIn(ctx, tx, deleteJobs, ids); err != nil {
+		return api.PurgeResponse{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return api.PurgeResponse{}, err
+	}
+	return api.PurgeResponse{Count: int64(len(ids))}, nil
+}
+
+// execIn : Expands the slice arguments of the IN clauses and executes the query in the transaction
+func execIn(ctx context.Context, tx *sqlx.Tx, query string, args ...any) error {
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, tx.Rebind(query), args...)
+	return err
+}
@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicas : Read replicas picked in round robin for the read only operations
+type replicas struct {
+	dbs  []*sqlx.DB
+	next uint64
+}
+
+// openReplicas : Opens the replica pools lazily so that an unreachable replica does not prevent the engine from starting
+func openReplicas(config Config) (*replicas, error) {
+	r := &replicas{}
+	for _, addr := range config.Replicas {
+		dsn, err := config.dsnFor(addr)
+		if err != nil {
+			_ = r.close()
+			return nil, err
+		}
+
+		db, err := sqlx.Open("mysql", dsn)
+		if err != nil {
+			_ = r.close()
+			return nil, err
+		}
+		config.Pool.apply(db)
+		r.dbs = append(r.dbs, db)
+	}
+	return r, nil
+}
+
+func (r *replicas) pick() *sqlx.DB {
+	if len(r.dbs) == 0 {
+		return nil
+	}
+	n := atomic.AddUint64(&r.next, 1)
+	return r.dbs[(n-1)%uint64(len(r.dbs))]
+}
+
+func (r *replicas) close() error {
+	var err error
+	for _, db := range r.dbs {
+		if cerr := db.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// read : Runs a read only query on a replica, falling back to the primary if there is no replica or the replica fails
+func (s *Engine) read(ctx context.Context, fn func(db *sqlx.DB) error) error {
+	if db := s.replicas.pick(); db != nil {
+		err := fn(db)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		s.logger.Error(err, "replica read failed, falling back to primary")
+	}
+	return fn(s.DB)
+}
@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+func Test_replicas_pick(t *testing.T) {
+	r, err := openReplicas(Config{User: "root", DBName: "prio", Replicas: []string{"r1:3306", "r2:3306"}})
+	if err != nil {
+		t.Fatalf("openReplicas() err=%v", err)
+	}
+	t.Cleanup(func() { _ = r.close() })
+
+	for i := 0; i < 4; i++ {
+		if got, want := r.pick(), r.dbs[i%2]; got != want {
+			t.Errorf("pick() call=%d got replica %p, want %p", i, got, want)
+		}
+	}
+
+	if got := (&replicas{}).pick(); got != nil {
+		t.Errorf("pick() without replicas got = %v, want nil", got)
+	}
+}
+
+func TestEngine_GetTopics_ReplicaFallback(t *testing.T) {
+	s := newTestEngine(t)
+	ctx := context.Background()
+	_, _ = s.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+
+	// nothing listens on port 1, every read fails on the replica and is served by the primary
+	r, err := openReplicas(Config{User: s.config.User, DBName: s.config.DBName, Replicas: []string{"127.0.0.1:1"}})
+	if err != nil {
+		t.Fatalf("openReplicas() err=%v", err)
+	}
+	s.replicas = r
+
+	topics, err := s.GetTopics(ctx)
+	if err != nil || len(topics) != 1 || topics[0] != "t1" {
+		t.Errorf("GetTopics() got = %v, err=%v", topics, err)
+	}
+}
@@ -0,0 +1,20 @@
+package postgres
+
+import "fmt"
+
+type Config struct {
+	Host     string
+	Port     int32
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string // SSLMode: disable, require, verify-ca or verify-full, defaults to disable
+}
+
+func (c Config) dsn() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", c.Host, c.Port, c.User, c.Password, c.DBName, sslMode)
+}
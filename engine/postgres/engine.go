@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/postgres/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	allTopics   = `SELECT topics.name from topics`
+	pendingJobs = `SELECT COUNT(*) from jobs where jobs.topic = $1 AND jobs.status = $2`
+	addTopic    = `INSERT INTO topics(name, description, created_at, updated_at) VALUES ($1, $2, $3, $4)`
+
+	addJob = `INSERT INTO jobs(topic, payload, headers, priority, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	// claimJob picks the top priority pending job skipping the rows locked by concurrent consumers and claims it in a single statement
+	claimJob = `UPDATE jobs SET status = $1, claimed_at = $2, claimed_by = $3
+		WHERE jobs.id = (
+			SELECT jobs.id FROM jobs WHERE jobs.topic = $4 AND jobs.status = $5
+			ORDER BY priority DESC, updated_at, id LIMIT 1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority`
+
+	jobById     = `SELECT jobs.id, jobs.status, jobs.claimed_by from jobs where jobs.id = $1 FOR UPDATE`
+	getJob      = `SELECT jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority, jobs.status, jobs.claimed_by from jobs where jobs.id = $1`
+	completeJob = `UPDATE jobs SET status = $1, completed_at = $2 WHERE jobs.id = $3`
+	extendLease = `UPDATE jobs SET claimed_at = $1 WHERE jobs.id = $2`
+	nackJob     = `UPDATE jobs SET status = $1, claimed_at = $2, claimed_by = $3, updated_at = $4 WHERE jobs.id = $5`
+
+	reQueue = `UPDATE jobs SET status = $1, claimed_at = $2, claimed_by = $3, updated_at = $4 WHERE jobs.topic = $5 AND jobs.status = $6 AND jobs.claimed_at < $7`
+)
+
+type (
+	Engine struct {
+		*sqlx.DB
+		config Config
+		logger commons.Logger
+
+		listener *pq.Listener
+		waiters  *waiters
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+// NewEngine : Connects to postgres and starts listening for pending job notifications
+func NewEngine(config Config, opts ...Option) (*Engine, error) {
+	db, err := sqlx.Connect("postgres", config.dsn())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Engine{
+		DB:      db,
+		config:  config,
+		logger:  &commons.DefaultLogger{},
+		waiters: newWaiters(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	err = s.listen()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close : Stops listening for notifications and closes the database
+func (s *Engine) Close() error {
+	if err := s.listener.Close(); err != nil {
+		s.logger.Error(err, "error closing listener")
+	}
+	return s.DB.Close()
+}
+
+func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	_, err := s.ExecContext(ctx, addTopic, req.Name, req.Description, time.Now().UnixMilli(), time.Now().UnixMilli())
+	if err != nil {
+		return api.RegisterTopicResponse{}, err
+	}
+	s.logger.Info("topic %s registered successfully id", req.Name)
+	return api.RegisterTopicResponse{}, nil
+}
+
+func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	var id int64
+	err := s.QueryRowxContext(ctx, addJob, req.Topic, req.Payload, commons.Headers(req.Headers), req.Priority, models.PENDING, time.Now().UnixMilli(), time.Now().UnixMilli()).Scan(&id)
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+	s.logger.Info("job enqueued id=%d", id)
+	return api.EnqueueResponse{JobId: id}, nil
+}
+
+func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	var job models.Job
+	err := s.QueryRowxContext(ctx, claimJob, models.CLAIMED, time.Now().UnixMilli(), req.Consumer, req.Topic, models.PENDING).StructScan(&job)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.DequeueResponse{}, nil
+		}
+		return api.DequeueResponse{}, err
+	}
+
+	return api.DequeueResponse{
+		JobId:    job.ID,
+		Topic:    job.Topic,
+		Payload:  job.Payload,
+		Priority: job.Priority,
+		Headers:  job.Headers,
+	}, nil
+}
+
+func (s *Engine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	var job models.Job
+	if err := s.GetContext(ctx, &job, getJob, req.JobId); err != nil {
+		if err == sql.ErrNoRows {
+			return api.GetJobResponse{}, api.ErrorJobNotPresent
+		}
+		return api.GetJobResponse{}, err
+	}
+	return api.GetJobResponse{
+		JobId:     job.ID,
+		Topic:     job.Topic,
+		Payload:   job.Payload,
+		Priority:  job.Priority,
+		Headers:   job.Headers,
+		Status:    api.JobStatus(job.Status),
+		ClaimedBy: job.ClaimedBy.String,
+	}, nil
+}
+
+func (s *Engine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	err := s.lease(ctx, req.JobId, req.Consumer, "ack", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, completeJob, models.COMPLETED, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.AckResponse{Acked: false}, err
+	}
+	return api.AckResponse{Acked: true}, nil
+}
+
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (s *Engine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.lease(ctx, req.JobId, req.Consumer, "extend lease", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, extendLease, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// Nack : Moves the job back to pending, it is queued behind the pending jobs of the same priority
+func (s *Engine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	err := s.lease(ctx, req.JobId, req.Consumer, "nack", func(tx *sqlx.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, nackJob, models.PENDING, nil, nil, time.Now().UnixMilli(), req.JobId)
+	})
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+// lease : Locks the job, checks it is claimed by the consumer and runs update in the same transaction
+func (s *Engine) lease(ctx context.Context, id int64, consumer string, op string, update func(tx *sqlx.Tx) (sql.Result, error)) error {
+	tx, err := s.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Error(err, "error during "+op)
+		}
+	}()
+
+	var job models.Job
+	err = tx.GetContext(ctx, &job, jobById, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.ErrorJobNotPresent
+		}
+		return err
+	}
+
+	if job.Status == models.COMPLETED {
+		return api.ErrorAlreadyAcked
+	}
+
+	if job.Status == models.PENDING {
+		return api.ErrorLeaseExceeded
+	}
+
+	if job.Status == models.CLAIMED && job.ClaimedBy.String != consumer {
+		return api.ErrorWrongConsumer
+	}
+
+	result, err := update(tx)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return api.ErrorGeneral
+	}
+
+	return tx.Commit()
+}
+
+func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	result, err := s.ExecContext(ctx, reQueue, models.PENDING, nil, nil, time.Now().UnixMilli(), req.Topic, models.CLAIMED, req.RequeueTs)
+	if err != nil {
+		return api.RequeueResponse{}, err
+	}
+	rows, _ := result.RowsAffected()
+	return api.RequeueResponse{Count: rows}, nil
+}
+
+func (s *Engine) GetTopics(ctx context.Context) ([]string, error) {
+	var topics []string
+	err := s.SelectContext(ctx, &topics, allTopics)
+	if err != nil {
+		return []string{}, err
+	}
+	return topics, nil
+}
+
+// PendingCount : Returns the number of pending jobs of the topic
+func (s *Engine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	var count int64
+	err := s.GetContext(ctx, &count, pendingJobs, topic, models.PENDING)
+	return count, err
+}
+
+// IsTransient : Reports whether the error may go away on retry, a serialization failure, a deadlock, a lock not available
+// or one of the connection failures of api.IsTransient. It is the Retryable of api.Retry for this engine
+func IsTransient(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", "40P01", "55P03":
+			return true
+		}
+		return false
+	}
+	return api.IsTransient(err)
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+	"github.com/lib/pq"
+)
+
+// newTestEngine : Connects to the database configured by PRIO_TEST_PG_* env and re-creates the schema.
+// The tests are skipped if PRIO_TEST_PG_HOST is not set
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	host := os.Getenv("PRIO_TEST_PG_HOST")
+	if host == "" {
+		t.Skip("PRIO_TEST_PG_HOST not set, skipping postgres tests")
+	}
+
+	port, _ := strconv.Atoi(os.Getenv("PRIO_TEST_PG_PORT"))
+	if port == 0 {
+		port = 5432
+	}
+	s, err := NewEngine(Config{
+		Host:     host,
+		Port:     int32(port),
+		User:     os.Getenv("PRIO_TEST_PG_USER"),
+		Password: os.Getenv("PRIO_TEST_PG_PASSWORD"),
+		DBName:   os.Getenv("PRIO_TEST_PG_DATABASE"),
+	})
+	if err != nil {
+		t.Fatalf("error connecting to postgres err=%v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	_, err = s.Exec(`DROP TABLE IF EXISTS jobs, topics CASCADE; DROP FUNCTION IF EXISTS notify_job_pending() CASCADE`)
+	if err != nil {
+		t.Fatalf("error dropping schema err=%v", err)
+	}
+	runMigrations(t, s)
+	return s
+}
+
+func runMigrations(t *testing.T, s *Engine) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = s.Exec(string(data)); err != nil {
+			t.Fatalf("error applying migration file=%s err=%v", f, err)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		return newTestEngine(t)
+	})
+}
+
+func TestEngine_WaitForJob(t *testing.T) {
+	s := newTestEngine(t)
+	ctx := context.Background()
+	_, _ = s.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+
+	errCh := make(chan error, 1)
+	go func() {
+		wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		errCh <- s.WaitForJob(wctx, "t1")
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	_, _ = s.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+
+	if err := <-errCh; err != nil {
+		t.Errorf("WaitForJob() err=%v", err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, want: true},
+		{name: "deadlock", err: fmt.Errorf("ack: %w", &pq.Error{Code: "40P01"}), want: true},
+		{name: "lock not available", err: &pq.Error{Code: "55P03"}, want: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}},
+		{name: "contract error", err: api.ErrorWrongConsumer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var _ api.Notifier = (*Engine)(nil)
@@ -0,0 +1,13 @@
+module github.com/hextechpal/prio/engine/postgres
+
+go 1.19
+
+require (
+	github.com/hextechpal/prio/core v0.0.0-20221125150718-3fe15c6f3658
+	github.com/jmoiron/sqlx v1.3.5
+	github.com/lib/pq v1.10.7
+)
+
+require github.com/google/uuid v1.3.0 // indirect
+
+replace github.com/hextechpal/prio/core => ../../core
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/hextechpal/prio/core/commons"
+)
+
+type Status int
+
+const (
+	PENDING   Status = iota // Represents the job is just inserted in to the database
+	CLAIMED                 // The job has been claimed by the consumer
+	COMPLETED               // The job has been marked completed by the consumer
+)
+
+type Job struct {
+	ID       int64           `db:"id"`
+	Topic    string          `db:"topic"`
+	Payload  []byte          `db:"payload"`
+	Headers  commons.Headers `db:"headers"`
+	Priority int32           `db:"priority"`
+	Status   Status          `db:"status"`
+
+	ClaimedAt int64          `db:"claimed_at"`
+	ClaimedBy sql.NullString `db:"claimed_by"`
+
+	CompletedAt int64 `db:"completed_at"`
+
+	CreatedAt int64 `db:"created_at"`
+	UpdatedAt int64 `db:"updated_at"`
+}
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL primary key NOT NULL,
+    topic VARCHAR(20) NOT NULL REFERENCES topics(name),
+    payload BYTEA,
+    priority INT NOT NULL,
+    status SMALLINT default 1,
+
+    claimed_at BIGINT DEFAULT NULL,
+    claimed_by VARCHAR(50) DEFAULT NULL,
+
+    completed_at BIGINT DEFAULT NULL,
+
+    created_at BIGINT DEFAULT NULL,
+    updated_at BIGINT DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS top_job_idx ON jobs(topic, status, priority DESC, updated_at ASC);
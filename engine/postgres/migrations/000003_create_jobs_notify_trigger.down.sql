@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS job_pending_notify ON jobs;
+DROP FUNCTION IF EXISTS notify_job_pending();
@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION notify_job_pending() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('prio_jobs', NEW.topic);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- status 0 is PENDING, fired for new jobs and re-queued ones
+CREATE TRIGGER job_pending_notify
+    AFTER INSERT OR UPDATE OF status ON jobs
+    FOR EACH ROW WHEN (NEW.status = 0)
+    EXECUTE FUNCTION notify_job_pending();
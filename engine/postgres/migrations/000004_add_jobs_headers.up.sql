@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN headers JSONB DEFAULT NULL;
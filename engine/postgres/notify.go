@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	jobsChannel = "prio_jobs"
+
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+)
+
+// waiters : Consumers waiting for a pending job per topic.
+// A channel is closed to wake up everyone waiting on the topic
+type waiters struct {
+	mu     sync.Mutex
+	topics map[string]chan struct{}
+}
+
+func newWaiters() *waiters {
+	return &waiters{topics: make(map[string]chan struct{})}
+}
+
+func (w *waiters) wait(topic string) <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, ok := w.topics[topic]
+	if !ok {
+		ch = make(chan struct{})
+		w.topics[topic] = ch
+	}
+	return ch
+}
+
+func (w *waiters) notify(topic string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ch, ok := w.topics[topic]; ok {
+		close(ch)
+		delete(w.topics, topic)
+	}
+}
+
+func (w *waiters) notifyAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for topic, ch := range w.topics {
+		close(ch)
+		delete(w.topics, topic)
+	}
+}
+
+// WaitForJob : Blocks until a job becomes pending for the topic or the context is done, it makes the engine an api.Notifier.
+// A nil return only means a job may be available, the caller should Dequeue to claim it
+func (s *Engine) WaitForJob(ctx context.Context, topic string) error {
+	select {
+	case <-s.waiters.wait(topic):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// listen : Subscribes to the notifications sent by the job_pending_notify trigger
+func (s *Engine) listen() error {
+	s.listener = pq.NewListener(s.config.dsn(), minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			s.logger.Error(err, "listener event=%d", event)
+		}
+	})
+
+	err := s.listener.Listen(jobsChannel)
+	if err != nil {
+		_ = s.listener.Close()
+		return err
+	}
+
+	go func() {
+		for n := range s.listener.Notify {
+			if n == nil {
+				// connection was re-established and notifications might have been lost
+				s.waiters.notifyAll()
+				continue
+			}
+			s.waiters.notify(n.Extra)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,15 @@
+package redis
+
+type Config struct {
+	Addr     string // Addr: host:port of the redis server
+	Password string
+	DB       int
+	Prefix   string // Prefix: prepended to all the keys, defaults to prio
+}
+
+func (c Config) prefix() string {
+	if c.Prefix == "" {
+		return "prio"
+	}
+	return c.Prefix
+}
@@ -0,0 +1,299 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	errorTopicExists = errors.New("topic already registered")
+
+	// ackErrors maps the ack script results to api errors
+	ackErrors = map[string]error{
+		"not_present":    api.ErrorJobNotPresent,
+		"already_acked":  api.ErrorAlreadyAcked,
+		"lease_exceeded": api.ErrorLeaseExceeded,
+		"wrong_consumer": api.ErrorWrongConsumer,
+	}
+
+	// jobStatuses maps the status of the job hashes to api statuses
+	jobStatuses = map[string]api.JobStatus{
+		"pending":   api.JobPending,
+		"claimed":   api.JobClaimed,
+		"completed": api.JobCompleted,
+	}
+)
+
+type (
+	// Engine : Storage engine on top of redis.
+	// Pending jobs are kept in a sorted set per topic scored by the inverted priority,
+	// claimed jobs in a sorted set per topic scored by the claim time.
+	// All the multi key operations run as lua scripts so that they are atomic
+	Engine struct {
+		client *redis.Client
+		config Config
+		logger commons.Logger
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+func NewEngine(config Config, opts ...Option) (*Engine, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	s := &Engine{
+		client: client,
+		config: config,
+		logger: &commons.DefaultLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *Engine) Close() error {
+	return s.client.Close()
+}
+
+func (s *Engine) GetTopics(ctx context.Context) ([]string, error) {
+	topics, err := s.client.HKeys(ctx, s.topicsKey()).Result()
+	if err != nil {
+		return []string{}, err
+	}
+	return topics, nil
+}
+
+func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	ok, err := s.client.HSetNX(ctx, s.topicsKey(), req.Name, req.Description).Result()
+	if err != nil {
+		return api.RegisterTopicResponse{}, err
+	}
+
+	if !ok {
+		return api.RegisterTopicResponse{}, errorTopicExists
+	}
+	s.logger.Info("topic %s registered successfully id", req.Name)
+	return api.RegisterTopicResponse{}, nil
+}
+
+func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	headers, err := encodeHeaders(req.Headers)
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+
+	keys := []string{s.topicsKey(), s.seqKey(), s.pendingKey(req.Topic)}
+	id, err := enqueueScript.Run(ctx, s.client, keys, s.jobPrefix(), req.Topic, req.Payload, req.Priority, time.Now().UnixMilli(), headers).Int64()
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+	s.logger.Info("job enqueued id=%d", id)
+	return api.EnqueueResponse{JobId: id}, nil
+}
+
+func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	keys := []string{s.pendingKey(req.Topic), s.claimedKey(req.Topic)}
+	res, err := dequeueScript.Run(ctx, s.client, keys, s.jobPrefix(), req.Consumer, time.Now().UnixMilli()).Slice()
+	if err != nil {
+		if err == redis.Nil {
+			return api.DequeueResponse{}, nil
+		}
+		return api.DequeueResponse{}, err
+	}
+
+	id, err := strconv.ParseInt(res[0].(string), 10, 64)
+	if err != nil {
+		return api.DequeueResponse{}, err
+	}
+
+	priority, err := strconv.ParseInt(res[2].(string), 10, 32)
+	if err != nil {
+		return api.DequeueResponse{}, err
+	}
+
+	headers, err := decodeHeaders(res[3].(string))
+	if err != nil {
+		return api.DequeueResponse{}, err
+	}
+
+	return api.DequeueResponse{
+		JobId:    id,
+		Topic:    req.Topic,
+		Payload:  []byte(res[1].(string)),
+		Priority: int32(priority),
+		Headers:  headers,
+	}, nil
+}
+
+func (s *Engine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	fields, err := s.client.HGetAll(ctx, s.jobKey(req.JobId)).Result()
+	if err != nil {
+		return api.GetJobResponse{}, err
+	}
+	if len(fields) == 0 {
+		return api.GetJobResponse{}, api.ErrorJobNotPresent
+	}
+
+	priority, err := strconv.ParseInt(fields["priority"], 10, 32)
+	if err != nil {
+		return api.GetJobResponse{}, err
+	}
+
+	headers, err := decodeHeaders(fields["headers"])
+	if err != nil {
+		return api.GetJobResponse{}, err
+	}
+
+	status, ok := jobStatuses[fields["status"]]
+	if !ok {
+		return api.GetJobResponse{}, fmt.Errorf("job=%d has an unknown status %s", req.JobId, fields["status"])
+	}
+
+	return api.GetJobResponse{
+		JobId:     req.JobId,
+		Topic:     fields["topic"],
+		Payload:   []byte(fields["payload"]),
+		Priority:  int32(priority),
+		Headers:   headers,
+		Status:    status,
+		ClaimedBy: fields["claimed_by"],
+	}, nil
+}
+
+func (s *Engine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	errRes := api.AckResponse{Acked: false}
+	jobKey := s.jobKey(req.JobId)
+
+	// topic of a job never changes, it is read outside the script to declare the claimed key
+	topic, err := s.client.HGet(ctx, jobKey, "topic").Result()
+	if err != nil {
+		if err == redis.Nil {
+			return errRes, api.ErrorJobNotPresent
+		}
+		return errRes, err
+	}
+
+	res, err := ackScript.Run(ctx, s.client, []string{jobKey, s.claimedKey(topic)}, req.Consumer, time.Now().UnixMilli()).Text()
+	if err != nil {
+		return errRes, err
+	}
+
+	if err, ok := ackErrors[res]; ok {
+		return errRes, err
+	}
+	return api.AckResponse{Acked: true}, nil
+}
+
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (s *Engine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	return s.lease(ctx, extendScript, req)
+}
+
+// Nack : Queues the job behind the pending jobs of the same priority, like an expired job
+func (s *Engine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	return s.lease(ctx, nackScript, req)
+}
+
+// lease : Runs a script on a claimed job with the keys job, claimed, pending, seq and the args consumer, now
+func (s *Engine) lease(ctx context.Context, script *redis.Script, req api.LeaseRequest) (api.LeaseResponse, error) {
+	jobKey := s.jobKey(req.JobId)
+	topic, err := s.client.HGet(ctx, jobKey, "topic").Result()
+	if err != nil {
+		if err == redis.Nil {
+			return api.LeaseResponse{}, api.ErrorJobNotPresent
+		}
+		return api.LeaseResponse{}, err
+	}
+
+	keys := []string{jobKey, s.claimedKey(topic), s.pendingKey(topic), s.seqKey()}
+	res, err := script.Run(ctx, s.client, keys, req.Consumer, time.Now().UnixMilli()).Text()
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+
+	if err, ok := ackErrors[res]; ok {
+		return api.LeaseResponse{}, err
+	}
+	return api.LeaseResponse{Ok: true}, nil
+}
+
+func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	keys := []string{s.pendingKey(req.Topic), s.claimedKey(req.Topic), s.seqKey()}
+	count, err := requeueScript.Run(ctx, s.client, keys, s.jobPrefix(), req.RequeueTs, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return api.RequeueResponse{}, err
+	}
+	return api.RequeueResponse{Count: count}, nil
+}
+
+// PendingCount : Returns the number of pending jobs of the topic
+func (s *Engine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	return s.client.ZCard(ctx, s.pendingKey(topic)).Result()
+}
+
+// encodeHeaders : Headers are stored as a json object in the job hash, empty if the job has none
+func encodeHeaders(headers map[string]string) (string, error) {
+	if len(headers) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(headers)
+	return string(data), err
+}
+
+func decodeHeaders(data string) (map[string]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var headers map[string]string
+	err := json.Unmarshal([]byte(data), &headers)
+	return headers, err
+}
+
+func (s *Engine) topicsKey() string {
+	return fmt.Sprintf("%s:topics", s.config.prefix())
+}
+
+func (s *Engine) seqKey() string {
+	return fmt.Sprintf("%s:seq", s.config.prefix())
+}
+
+func (s *Engine) pendingKey(topic string) string {
+	return fmt.Sprintf("%s:pending:%s", s.config.prefix(), topic)
+}
+
+func (s *Engine) claimedKey(topic string) string {
+	return fmt.Sprintf("%s:claimed:%s", s.config.prefix(), topic)
+}
+
+func (s *Engine) jobPrefix() string {
+	return fmt.Sprintf("%s:job:", s.config.prefix())
+}
+
+func (s *Engine) jobKey(id int64) string {
+	return fmt.Sprintf("%s%d", s.jobPrefix(), id)
+}
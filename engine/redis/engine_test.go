@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+)
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	s, err := NewEngine(Config{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatalf("NewEngine() err=%v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		return newTestEngine(t)
+	})
+}
@@ -0,0 +1,19 @@
+module github.com/hextechpal/prio/engine/redis
+
+go 1.19
+
+require (
+	github.com/alicebob/miniredis/v2 v2.30.0
+	github.com/hextechpal/prio/core v0.0.0-20221125150718-3fe15c6f3658
+	github.com/redis/go-redis/v9 v9.0.2
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/yuin/gopher-lua v0.0.0-20220504180219-658193537a64 // indirect
+)
+
+replace github.com/hextechpal/prio/core => ../../core
@@ -0,0 +1,98 @@
+package redis
+
+import "github.com/redis/go-redis/v9"
+
+// Job hashes are stored at <prefix>:job:<id>, the job key prefix is passed as an argument.
+// Pending members are "<sequence>:<id>" with the sequence zero padded, so that jobs of the same priority
+// (same score) are ordered lexicographically by their enqueue (or re-queue) sequence.
+
+// claimedCheck returns the reason the job KEYS[1] is not claimed by the consumer ARGV[1], the results are mapped by ackErrors
+const claimedCheck = `
+local job = redis.call('HMGET', KEYS[1], 'status', 'claimed_by')
+if not job[1] then
+	return 'not_present'
+end
+if job[1] == 'completed' then
+	return 'already_acked'
+end
+if job[1] == 'pending' then
+	return 'lease_exceeded'
+end
+if job[2] ~= ARGV[1] then
+	return 'wrong_consumer'
+end
+`
+
+var (
+	// enqueueScript KEYS: topics, seq, pending ARGV: job prefix, topic, payload, priority, now, headers(json)
+	enqueueScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[2]) == 0 then
+	return redis.error_reply('topic not registered')
+end
+local id = redis.call('INCR', KEYS[2])
+redis.call('HSET', ARGV[1] .. id,
+	'topic', ARGV[2], 'payload', ARGV[3], 'priority', ARGV[4], 'status', 'pending',
+	'created_at', ARGV[5], 'updated_at', ARGV[5])
+if ARGV[6] ~= '' then
+	redis.call('HSET', ARGV[1] .. id, 'headers', ARGV[6])
+end
+redis.call('ZADD', KEYS[3], -tonumber(ARGV[4]), string.format('%020d:%d', id, id))
+return id
+`)
+
+	// dequeueScript KEYS: pending, claimed ARGV: job prefix, consumer, now
+	dequeueScript = redis.NewScript(`
+local top = redis.call('ZRANGE', KEYS[1], 0, 0)
+if #top == 0 then
+	return false
+end
+redis.call('ZREM', KEYS[1], top[1])
+local id = string.match(top[1], ':(%d+)$')
+local key = ARGV[1] .. id
+redis.call('HSET', key, 'status', 'claimed', 'claimed_at', ARGV[3], 'claimed_by', ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], id)
+local job = redis.call('HMGET', key, 'payload', 'priority', 'headers')
+return {id, job[1], job[2], job[3] or ''}
+`)
+
+	// ackScript KEYS: job, claimed ARGV: consumer, now
+	ackScript = redis.NewScript(claimedCheck + `
+redis.call('HSET', KEYS[1], 'status', 'completed', 'completed_at', ARGV[2])
+redis.call('ZREM', KEYS[2], string.match(KEYS[1], ':(%d+)$'))
+return 'acked'
+`)
+
+	// extendScript KEYS: job, claimed ARGV: consumer, now
+	extendScript = redis.NewScript(claimedCheck + `
+redis.call('HSET', KEYS[1], 'claimed_at', ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[2], string.match(KEYS[1], ':(%d+)$'))
+return 'ok'
+`)
+
+	// nackScript KEYS: job, claimed, pending, seq ARGV: consumer, now
+	nackScript = redis.NewScript(claimedCheck + `
+local id = string.match(KEYS[1], ':(%d+)$')
+local priority = redis.call('HGET', KEYS[1], 'priority')
+local seq = redis.call('INCR', KEYS[4])
+redis.call('HSET', KEYS[1], 'status', 'pending', 'updated_at', ARGV[2])
+redis.call('HDEL', KEYS[1], 'claimed_at', 'claimed_by')
+redis.call('ZREM', KEYS[2], id)
+redis.call('ZADD', KEYS[3], -tonumber(priority), string.format('%020d:%s', seq, id))
+return 'ok'
+`)
+
+	// requeueScript KEYS: pending, claimed, seq ARGV: job prefix, requeue ts, now
+	requeueScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', '(' .. ARGV[2])
+for _, id in ipairs(ids) do
+	local key = ARGV[1] .. id
+	local priority = redis.call('HGET', key, 'priority')
+	local seq = redis.call('INCR', KEYS[3])
+	redis.call('HSET', key, 'status', 'pending', 'updated_at', ARGV[3])
+	redis.call('HDEL', key, 'claimed_at', 'claimed_by')
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], -tonumber(priority), string.format('%020d:%s', seq, id))
+end
+return #ids
+`)
+)
@@ -0,0 +1,251 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+)
+
+const (
+	// shardBits high bits of a job id, below the sign bit, hold the shard index
+	shardBits  = 8
+	shardShift = 63 - shardBits
+	maxShards  = 1 << shardBits
+	localMask  = int64(1)<<shardShift - 1
+)
+
+var (
+	errorNoShards      = errors.New("at least one shard is required")
+	errorTooManyShards = fmt.Errorf("at most %d shards are supported", maxShards)
+	errorJobIdOverflow = errors.New("job id of the shard does not fit in the sharded job id")
+	errorUnknownShard  = errors.New("topic is mapped to an unknown shard")
+)
+
+type (
+	// Engine : Spreads the topics over several engines. A topic lives entirely on one shard, picked from the
+	// topic map or by hashing the topic name. Job ids carry the shard index in their high bits so that acks
+	// are routed to the right shard without a lookup
+	Engine struct {
+		shards []api.Engine
+		topics map[string]int // topics: explicit topic to shard index assignment, other topics are hashed
+		logger commons.Logger
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+// WithTopicMap : Pins topics to shard indexes, the topics not in the map are assigned by hash
+func WithTopicMap(topics map[string]int) Option {
+	return func(s *Engine) {
+		s.topics = topics
+	}
+}
+
+// NewEngine : Returns an engine routing every topic to one of the shards. The shard order must stay the same
+// across restarts as the job ids and the hash assignment depend on it
+func NewEngine(shards []api.Engine, opts ...Option) (*Engine, error) {
+	if len(shards) == 0 {
+		return nil, errorNoShards
+	}
+
+	if len(shards) > maxShards {
+		return nil, errorTooManyShards
+	}
+
+	s := &Engine{
+		shards: shards,
+		topics: map[string]int{},
+		logger: &commons.DefaultLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for topic, idx := range s.topics {
+		if idx < 0 || idx >= len(shards) {
+			return nil, fmt.Errorf("%w: topic=%s shard=%d", errorUnknownShard, topic, idx)
+		}
+	}
+	return s, nil
+}
+
+func (s *Engine) GetTopics(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	for idx, shard := range s.shards {
+		topics, err := shard.GetTopics(ctx)
+		if err != nil {
+			return []string{}, fmt.Errorf("shard=%d: %w", idx, err)
+		}
+		for _, topic := range topics {
+			seen[topic] = true
+		}
+	}
+
+	topics := make([]string, 0, len(seen))
+	for topic := range seen {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
+
+func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	return s.shards[s.shardOf(req.Name)].RegisterTopic(ctx, req)
+}
+
+func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	idx := s.shardOf(req.Topic)
+	res, err := s.shards[idx].Enqueue(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	res.JobId, err = encode(idx, res.JobId)
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+	return res, nil
+}
+
+func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	idx := s.shardOf(req.Topic)
+	res, err := s.shards[idx].Dequeue(ctx, req)
+	if err != nil || res.JobId == 0 {
+		return res, err
+	}
+
+	res.JobId, err = encode(idx, res.JobId)
+	if err != nil {
+		return api.DequeueResponse{}, err
+	}
+	return res, nil
+}
+
+func (s *Engine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	idx, id := decode(req.JobId)
+	if idx >= len(s.shards) {
+		return api.GetJobResponse{}, api.ErrorJobNotPresent
+	}
+
+	jobId := req.JobId
+	req.JobId = id
+	res, err := s.shards[idx].GetJob(ctx, req)
+	if err != nil {
+		return api.GetJobResponse{}, err
+	}
+	res.JobId = jobId
+	return res, nil
+}
+
+func (s *Engine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	idx, id := decode(req.JobId)
+	if idx >= len(s.shards) {
+		return api.AckResponse{Acked: false}, api.ErrorJobNotPresent
+	}
+
+	req.JobId = id
+	return s.shards[idx].Ack(ctx, req)
+}
+
+// ExtendLease : Extends the lease of the job on its shard
+func (s *Engine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	leaser, err := s.leaserOf(&req)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return leaser.ExtendLease(ctx, req)
+}
+
+// Nack : Moves the job back to pending on its shard
+func (s *Engine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	leaser, err := s.leaserOf(&req)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+	return leaser.Nack(ctx, req)
+}
+
+// WaitForJob : Waits on the shard of the topic
+func (s *Engine) WaitForJob(ctx context.Context, topic string) error {
+	notifier, ok := s.shards[s.shardOf(topic)].(api.Notifier)
+	if !ok {
+		return api.ErrorNotSupported
+	}
+	return notifier.WaitForJob(ctx, topic)
+}
+
+// leaserOf : Returns the shard of the job and rewrites the job id to the shard local one
+func (s *Engine) leaserOf(req *api.LeaseRequest) (api.Leaser, error) {
+	idx, id := decode(req.JobId)
+	if idx >= len(s.shards) {
+		return nil, api.ErrorJobNotPresent
+	}
+
+	leaser, ok := s.shards[idx].(api.Leaser)
+	if !ok {
+		return nil, api.ErrorNotSupported
+	}
+	req.JobId = id
+	return leaser, nil
+}
+
+func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	return s.shards[s.shardOf(req.Topic)].ReQueue(ctx, req)
+}
+
+// Purge : Purges the topic on its shard
+func (s *Engine) Purge(ctx context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	purger, ok := s.shards[s.shardOf(req.Topic)].(api.Purger)
+	if !ok {
+		return api.PurgeResponse{}, api.ErrorNotSupported
+	}
+	return purger.Purge(ctx, req)
+}
+
+// PendingCount : Returns the pending count of the topic on its shard
+func (s *Engine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	inspector, ok := s.shards[s.shardOf(topic)].(api.Inspector)
+	if !ok {
+		return 0, api.ErrorNotSupported
+	}
+	return inspector.PendingCount(ctx, topic)
+}
+
+// shardOf : Returns the index of the shard holding the topic
+func (s *Engine) shardOf(topic string) int {
+	if idx, ok := s.topics[topic]; ok {
+		return idx
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(topic))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// encode : Stores the shard index in the high bits of the job id of the shard
+func encode(idx int, id int64) (int64, error) {
+	if id < 0 || id > localMask {
+		return 0, errorJobIdOverflow
+	}
+	return int64(idx)<<shardShift | id, nil
+}
+
+// decode : Splits a sharded job id in to the shard index and the job id of the shard
+func decode(id int64) (int, int64) {
+	if id < 0 {
+		return maxShards, 0
+	}
+	return int(id >> shardShift), id & localMask
+}
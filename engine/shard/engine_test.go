@@ -0,0 +1,101 @@
+package shard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/enginetest"
+	"github.com/hextechpal/prio/engine/memory"
+)
+
+func newTestEngine(t *testing.T, shards int, opts ...Option) *Engine {
+	t.Helper()
+	engines := make([]api.Engine, shards)
+	for i := range engines {
+		e, err := memory.NewEngine()
+		if err != nil {
+			t.Fatalf("memory.NewEngine() err=%v", err)
+		}
+		engines[i] = e
+	}
+
+	s, err := NewEngine(engines, opts...)
+	if err != nil {
+		t.Fatalf("NewEngine() err=%v", err)
+	}
+	return s
+}
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) api.Engine {
+		return newTestEngine(t, 3)
+	})
+}
+
+func TestEngine_TopicMap(t *testing.T) {
+	s := newTestEngine(t, 3, WithTopicMap(map[string]int{"t1": 2}))
+	ctx := context.Background()
+	_, _ = s.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+
+	if topics, _ := s.shards[2].GetTopics(ctx); len(topics) != 1 || topics[0] != "t1" {
+		t.Fatalf("RegisterTopic() shard=2 got topics = %v, want [t1]", topics)
+	}
+
+	res, err := s.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	if err != nil {
+		t.Fatalf("Enqueue() err=%v", err)
+	}
+	if idx, _ := decode(res.JobId); idx != 2 {
+		t.Errorf("Enqueue() job=%d encodes shard=%d, want 2", res.JobId, idx)
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	e, _ := memory.NewEngine()
+	tests := []struct {
+		name   string
+		shards []api.Engine
+		opts   []Option
+	}{
+		{name: "No shards"},
+		{name: "Unknown shard in topic map", shards: []api.Engine{e}, opts: []Option{WithTopicMap(map[string]int{"t1": 1})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine(tt.shards, tt.opts...); err == nil {
+				t.Errorf("NewEngine() should fail")
+			}
+		})
+	}
+}
+
+func Test_encode(t *testing.T) {
+	tests := []struct {
+		name    string
+		idx     int
+		id      int64
+		wantErr bool
+	}{
+		{name: "First shard", idx: 0, id: 42},
+		{name: "Last shard", idx: maxShards - 1, id: localMask},
+		{name: "Overflow", idx: 1, id: localMask + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encode(tt.idx, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encode() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got < 0 {
+				t.Errorf("encode() got negative id=%d", got)
+			}
+			if idx, id := decode(got); idx != tt.idx || id != tt.id {
+				t.Errorf("decode() got = (%d, %d), want (%d, %d)", idx, id, tt.idx, tt.id)
+			}
+		})
+	}
+}
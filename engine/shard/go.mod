@@ -0,0 +1,15 @@
+module github.com/hextechpal/prio/engine/shard
+
+go 1.19
+
+require (
+	github.com/hextechpal/prio/core v0.0.0-20221125150718-3fe15c6f3658
+	github.com/hextechpal/prio/engine/memory v0.0.0-20221125151452-105fca04192c
+)
+
+require github.com/google/uuid v1.3.0 // indirect
+
+replace (
+	github.com/hextechpal/prio/core => ../../core
+	github.com/hextechpal/prio/engine/memory => ../memory
+)
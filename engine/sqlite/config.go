@@ -0,0 +1,16 @@
+package sqlite
+
+import "fmt"
+
+type Config struct {
+	Path          string // Path: database file, created if it does not exist
+	BusyTimeoutMs int32  // BusyTimeoutMs: time a writer waits for the database lock, defaults to 5000
+}
+
+func (c Config) dsn() string {
+	busyTimeout := c.BusyTimeoutMs
+	if busyTimeout == 0 {
+		busyTimeout = 5000
+	}
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_pragma=foreign_keys(1)", c.Path, busyTimeout)
+}
@@ -0,0 +1,280 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/sqlite/internal/models"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	allTopics   = `SELECT topics.name from topics`
+	pendingJobs = `SELECT COUNT(*) from jobs where jobs.topic = ? AND jobs.status = ?`
+	addTopic    = `INSERT INTO topics(name, description, created_at, updated_at) VALUES (?, ?, ?, ?)`
+
+	addJob = `INSERT INTO jobs(topic, payload, headers, priority, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	// claimJob picks and claims the top priority pending job in a single statement
+	claimJob = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?
+		WHERE jobs.id = (
+			SELECT jobs.id FROM jobs WHERE jobs.topic = ? AND jobs.status = ?
+			ORDER BY priority DESC, updated_at, id LIMIT 1
+		)
+		RETURNING jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority`
+
+	// completeJob only completes the job if it is still claimed by the consumer
+	completeJob = `UPDATE jobs SET status = ?, completed_at = ? WHERE jobs.id = ? AND jobs.status = ? AND jobs.claimed_by = ?`
+	jobById     = `SELECT jobs.id, jobs.status, jobs.claimed_by from jobs where jobs.id = ?`
+	getJob      = `SELECT jobs.id, jobs.topic, jobs.payload, jobs.headers, jobs.priority, jobs.status, jobs.claimed_by from jobs where jobs.id = ?`
+
+	// extendLease and nackJob only update the job if it is still claimed by the consumer
+	extendLease = `UPDATE jobs SET claimed_at = ? WHERE jobs.id = ? AND jobs.status = ? AND jobs.claimed_by = ?`
+	nackJob     = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?, updated_at = ? WHERE jobs.id = ? AND jobs.status = ? AND jobs.claimed_by = ?`
+
+	reQueue = `UPDATE jobs SET status = ?, claimed_at = ?, claimed_by = ?, updated_at = ? WHERE jobs.topic = ? AND jobs.status = ? AND jobs.claimed_at < ?`
+)
+
+//go:embed migrations/*.up.sql
+var migrations embed.FS
+
+type (
+	Engine struct {
+		*sqlx.DB
+		config Config
+		logger commons.Logger
+	}
+
+	Option = func(s *Engine)
+)
+
+func WithLogger(logger commons.Logger) Option {
+	return func(s *Engine) {
+		s.logger = logger
+	}
+}
+
+// NewEngine : Opens (or creates) the database file and applies the schema
+func NewEngine(config Config, opts ...Option) (*Engine, error) {
+	db, err := sqlx.Connect("sqlite", config.dsn())
+	if err != nil {
+		return nil, err
+	}
+	// sqlite allows a single writer, serializing on one connection avoids busy errors
+	db.SetMaxOpenConns(1)
+
+	s := &Engine{
+		DB:     db,
+		config: config,
+		logger: &commons.DefaultLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err = s.createSchema(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// createSchema : Applies the migrations newer than the user_version of the database and records the last one applied
+func (s *Engine) createSchema() error {
+	files, err := fs.Glob(migrations, "migrations/*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	var version int
+	if err = s.Get(&version, `PRAGMA user_version`); err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		if i < version {
+			continue
+		}
+		data, err := migrations.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if _, err = s.Exec(string(data)); err != nil {
+			return err
+		}
+		if _, err = s.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Engine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	_, err := s.ExecContext(ctx, addTopic, req.Name, req.Description, time.Now().UnixMilli(), time.Now().UnixMilli())
+	if err != nil {
+		return api.RegisterTopicResponse{}, err
+	}
+	s.logger.Info("topic %s registered successfully id", req.Name)
+	return api.RegisterTopicResponse{}, nil
+}
+
+func (s *Engine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	r, err := s.ExecContext(ctx, addJob, req.Topic, req.Payload, commons.Headers(req.Headers), req.Priority, models.PENDING, time.Now().UnixMilli(), time.Now().UnixMilli())
+	if err != nil {
+		return api.EnqueueResponse{}, err
+	}
+	id, _ := r.LastInsertId()
+	s.logger.Info("job enqueued id=%d", id)
+	return api.EnqueueResponse{JobId: id}, err
+}
+
+func (s *Engine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	var job models.Job
+	err := s.QueryRowxContext(ctx, claimJob, models.CLAIMED, time.Now().UnixMilli(), req.Consumer, req.Topic, models.PENDING).StructScan(&job)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.DequeueResponse{}, nil
+		}
+		return api.DequeueResponse{}, err
+	}
+
+	return api.DequeueResponse{
+		JobId:    job.ID,
+		Topic:    job.Topic,
+		Payload:  job.Payload,
+		Priority: job.Priority,
+		Headers:  job.Headers,
+	}, nil
+}
+
+func (s *Engine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	var job models.Job
+	if err := s.GetContext(ctx, &job, getJob, req.JobId); err != nil {
+		if err == sql.ErrNoRows {
+			return api.GetJobResponse{}, api.ErrorJobNotPresent
+		}
+		return api.GetJobResponse{}, err
+	}
+	return api.GetJobResponse{
+		JobId:     job.ID,
+		Topic:     job.Topic,
+		Payload:   job.Payload,
+		Priority:  job.Priority,
+		Headers:   job.Headers,
+		Status:    api.JobStatus(job.Status),
+		ClaimedBy: job.ClaimedBy.String,
+	}, nil
+}
+
+func (s *Engine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	errRes := api.AckResponse{Acked: false}
+	result, err := s.ExecContext(ctx, completeJob, models.COMPLETED, time.Now().UnixMilli(), req.JobId, models.CLAIMED, req.Consumer)
+	if err != nil {
+		return errRes, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 1 {
+		return api.AckResponse{Acked: true}, nil
+	}
+
+	// nothing was completed, find out why
+	return errRes, s.notClaimed(ctx, req.JobId, req.Consumer)
+}
+
+// ExtendLease : Restarts the lease of the job as if it was claimed now
+func (s *Engine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	result, err := s.ExecContext(ctx, extendLease, time.Now().UnixMilli(), req.JobId, models.CLAIMED, req.Consumer)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 1 {
+		return api.LeaseResponse{Ok: true}, nil
+	}
+	return api.LeaseResponse{}, s.notClaimed(ctx, req.JobId, req.Consumer)
+}
+
+// Nack : Moves the job back to pending, it is queued behind the pending jobs of the same priority
+func (s *Engine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	result, err := s.ExecContext(ctx, nackJob, models.PENDING, nil, nil, time.Now().UnixMilli(), req.JobId, models.CLAIMED, req.Consumer)
+	if err != nil {
+		return api.LeaseResponse{}, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 1 {
+		return api.LeaseResponse{Ok: true}, nil
+	}
+	return api.LeaseResponse{}, s.notClaimed(ctx, req.JobId, req.Consumer)
+}
+
+// notClaimed : Returns why the job is not claimed by the consumer
+func (s *Engine) notClaimed(ctx context.Context, id int64, consumer string) error {
+	var job models.Job
+	err := s.GetContext(ctx, &job, jobById, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.ErrorJobNotPresent
+		}
+		return err
+	}
+
+	switch {
+	case job.Status == models.COMPLETED:
+		return api.ErrorAlreadyAcked
+	case job.Status == models.PENDING:
+		return api.ErrorLeaseExceeded
+	case job.Status == models.CLAIMED && job.ClaimedBy.String != consumer:
+		return api.ErrorWrongConsumer
+	default:
+		return api.ErrorGeneral
+	}
+}
+
+func (s *Engine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	result, err := s.ExecContext(ctx, reQueue, models.PENDING, nil, nil, time.Now().UnixMilli(), req.Topic, models.CLAIMED, req.RequeueTs)
+	if err != nil {
+		return api.RequeueResponse{}, err
+	}
+	rows, _ := result.RowsAffected()
+	return api.RequeueResponse{Count: rows}, nil
+}
+
+func (s *Engine) GetTopics(ctx context.Context) ([]string, error) {
+	var topics []string
+	err := s.SelectContext(ctx, &topics, allTopics)
+	if err != nil {
+		return []string{}, err
+	}
+	return topics, nil
+}
+
+// PendingCount : Returns the number of pending jobs of the topic
+func (s *Engine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	var count int64
+	err := s.GetContext(ctx, &count, pendingJobs, topic, models.PENDING)
+	return count, err
+}
+
+// IsTransient : Reports whether the error may go away on retry, the database being busy or locked by another connection.
+// It is the Retryable of api.Retry for this engine
+func IsTransient(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// the primary result code is in the low byte of the extended code
+		code := sqliteErr.Code() & 0xff
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+	return api.IsTransient(err)
+}
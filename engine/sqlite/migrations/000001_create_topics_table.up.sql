@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS topics (
+    name VARCHAR(20) primary key NOT NULL,
+    description VARCHAR(255) DEFAULT NULL,
+    created_at BIGINT DEFAULT NULL,
+    updated_at BIGINT DEFAULT NULL
+);
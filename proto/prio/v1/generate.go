@@ -0,0 +1,4 @@
+// Package priov1 : Protobuf messages and gRPC service of the prio worker
+package priov1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative prio/v1/prio.proto
@@ -0,0 +1,403 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: prio/v1/prio.proto
+
+package priov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PrioClient is the client API for Prio service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PrioClient interface {
+	// GetTopics : Lists the registered topics
+	GetTopics(ctx context.Context, in *GetTopicsRequest, opts ...grpc.CallOption) (*GetTopicsResponse, error)
+	// RegisterTopic : Creates a new topic
+	RegisterTopic(ctx context.Context, in *RegisterTopicRequest, opts ...grpc.CallOption) (*RegisterTopicResponse, error)
+	// Enqueue : Adds a job to a topic
+	Enqueue(ctx context.Context, in *EnqueueRequest, opts ...grpc.CallOption) (*EnqueueResponse, error)
+	// Dequeue : Claims the top priority job of a topic, the job is empty if the topic has none
+	Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*DequeueResponse, error)
+	// GetJob : Returns a job with its status
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error)
+	// Ack : Marks a claimed job as completed
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error)
+	// ReQueue : Moves the jobs of a topic claimed before requeue_ts back to pending, it is run periodically by the worker owning the topic
+	ReQueue(ctx context.Context, in *ReQueueRequest, opts ...grpc.CallOption) (*ReQueueResponse, error)
+	// Subscribe : Streams the jobs of a topic as they are claimed for the consumer, the jobs are acked with Ack.
+	// With max_in_flight the stream pauses while that many delivered jobs are neither acked nor re-queued
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Prio_SubscribeClient, error)
+}
+
+type prioClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPrioClient(cc grpc.ClientConnInterface) PrioClient {
+	return &prioClient{cc}
+}
+
+func (c *prioClient) GetTopics(ctx context.Context, in *GetTopicsRequest, opts ...grpc.CallOption) (*GetTopicsResponse, error) {
+	out := new(GetTopicsResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/GetTopics", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) RegisterTopic(ctx context.Context, in *RegisterTopicRequest, opts ...grpc.CallOption) (*RegisterTopicResponse, error) {
+	out := new(RegisterTopicResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/RegisterTopic", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) Enqueue(ctx context.Context, in *EnqueueRequest, opts ...grpc.CallOption) (*EnqueueResponse, error) {
+	out := new(EnqueueResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/Enqueue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*DequeueResponse, error) {
+	out := new(DequeueResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/Dequeue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error) {
+	out := new(GetJobResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error) {
+	out := new(AckResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/Ack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) ReQueue(ctx context.Context, in *ReQueueRequest, opts ...grpc.CallOption) (*ReQueueResponse, error) {
+	out := new(ReQueueResponse)
+	err := c.cc.Invoke(ctx, "/prio.v1.Prio/ReQueue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prioClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Prio_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Prio_ServiceDesc.Streams[0], "/prio.v1.Prio/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &prioSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Prio_SubscribeClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type prioSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *prioSubscribeClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PrioServer is the server API for Prio service.
+// All implementations must embed UnimplementedPrioServer
+// for forward compatibility
+type PrioServer interface {
+	// GetTopics : Lists the registered topics
+	GetTopics(context.Context, *GetTopicsRequest) (*GetTopicsResponse, error)
+	// RegisterTopic : Creates a new topic
+	RegisterTopic(context.Context, *RegisterTopicRequest) (*RegisterTopicResponse, error)
+	// Enqueue : Adds a job to a topic
+	Enqueue(context.Context, *EnqueueRequest) (*EnqueueResponse, error)
+	// Dequeue : Claims the top priority job of a topic, the job is empty if the topic has none
+	Dequeue(context.Context, *DequeueRequest) (*DequeueResponse, error)
+	// GetJob : Returns a job with its status
+	GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error)
+	// Ack : Marks a claimed job as completed
+	Ack(context.Context, *AckRequest) (*AckResponse, error)
+	// ReQueue : Moves the jobs of a topic claimed before requeue_ts back to pending, it is run periodically by the worker owning the topic
+	ReQueue(context.Context, *ReQueueRequest) (*ReQueueResponse, error)
+	// Subscribe : Streams the jobs of a topic as they are claimed for the consumer, the jobs are acked with Ack.
+	// With max_in_flight the stream pauses while that many delivered jobs are neither acked nor re-queued
+	Subscribe(*SubscribeRequest, Prio_SubscribeServer) error
+	mustEmbedUnimplementedPrioServer()
+}
+
+// UnimplementedPrioServer must be embedded to have forward compatible implementations.
+type UnimplementedPrioServer struct {
+}
+
+func (UnimplementedPrioServer) GetTopics(context.Context, *GetTopicsRequest) (*GetTopicsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTopics not implemented")
+}
+func (UnimplementedPrioServer) RegisterTopic(context.Context, *RegisterTopicRequest) (*RegisterTopicResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterTopic not implemented")
+}
+func (UnimplementedPrioServer) Enqueue(context.Context, *EnqueueRequest) (*EnqueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enqueue not implemented")
+}
+func (UnimplementedPrioServer) Dequeue(context.Context, *DequeueRequest) (*DequeueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dequeue not implemented")
+}
+func (UnimplementedPrioServer) GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedPrioServer) Ack(context.Context, *AckRequest) (*AckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedPrioServer) ReQueue(context.Context, *ReQueueRequest) (*ReQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReQueue not implemented")
+}
+func (UnimplementedPrioServer) Subscribe(*SubscribeRequest, Prio_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedPrioServer) mustEmbedUnimplementedPrioServer() {}
+
+// UnsafePrioServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PrioServer will
+// result in compilation errors.
+type UnsafePrioServer interface {
+	mustEmbedUnimplementedPrioServer()
+}
+
+func RegisterPrioServer(s grpc.ServiceRegistrar, srv PrioServer) {
+	s.RegisterService(&Prio_ServiceDesc, srv)
+}
+
+func _Prio_GetTopics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).GetTopics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/GetTopics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).GetTopics(ctx, req.(*GetTopicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_RegisterTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).RegisterTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/RegisterTopic",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).RegisterTopic(ctx, req.(*RegisterTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_Enqueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).Enqueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/Enqueue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).Enqueue(ctx, req.(*EnqueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_Dequeue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DequeueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).Dequeue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/Dequeue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).Dequeue(ctx, req.(*DequeueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/GetJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/Ack",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_ReQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrioServer).ReQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/prio.v1.Prio/ReQueue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrioServer).ReQueue(ctx, req.(*ReQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prio_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PrioServer).Subscribe(m, &prioSubscribeServer{stream})
+}
+
+type Prio_SubscribeServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
+
+type prioSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *prioSubscribeServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Prio_ServiceDesc is the grpc.ServiceDesc for Prio service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Prio_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "prio.v1.Prio",
+	HandlerType: (*PrioServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTopics",
+			Handler:    _Prio_GetTopics_Handler,
+		},
+		{
+			MethodName: "RegisterTopic",
+			Handler:    _Prio_RegisterTopic_Handler,
+		},
+		{
+			MethodName: "Enqueue",
+			Handler:    _Prio_Enqueue_Handler,
+		},
+		{
+			MethodName: "Dequeue",
+			Handler:    _Prio_Dequeue_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Prio_GetJob_Handler,
+		},
+		{
+			MethodName: "Ack",
+			Handler:    _Prio_Ack_Handler,
+		},
+		{
+			MethodName: "ReQueue",
+			Handler:    _Prio_ReQueue_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Prio_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "prio/v1/prio.proto",
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/app/internal/config"
+	"github.com/hextechpal/prio/core"
+	"github.com/spf13/cobra"
+)
+
+// adminCmd groups the commands managing the topic assignment overrides of a namespace
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Manages topic pins and worker cordons",
+	Long: `It manages the assignment overrides stored in zookeeper for the configured namespace.
+The leader applies the changes on the next re-balance`,
+}
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <topic> <worker>",
+	Short: "Pins a topic to a dedicated worker",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdmin(cmd, func(a *core.Admin) error {
+			return a.Pin(args[0], args[1])
+		})
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <topic>",
+	Short: "Removes the pin of a topic",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdmin(cmd, func(a *core.Admin) error {
+			return a.Unpin(args[0])
+		})
+	},
+}
+
+var cordonCmd = &cobra.Command{
+	Use:   "cordon <worker>",
+	Short: "Excludes a worker from the topic assignment",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdmin(cmd, func(a *core.Admin) error {
+			return a.Cordon(args[0])
+		})
+	},
+}
+
+var uncordonCmd = &cobra.Command{
+	Use:   "uncordon <worker>",
+	Short: "Makes a cordoned worker eligible for topic assignment again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdmin(cmd, func(a *core.Admin) error {
+			return a.Uncordon(args[0])
+		})
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the topic pins and worker cordons",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdmin(cmd, func(a *core.Admin) error {
+			pins, err := a.Pins()
+			if err != nil {
+				return err
+			}
+			for topic, worker := range pins {
+				fmt.Printf("pin\ttopic=%s\tworker=%s\n", topic, worker)
+			}
+
+			cordons, err := a.Cordons()
+			if err != nil {
+				return err
+			}
+			for worker := range cordons {
+				fmt.Printf("cordon\tworker=%s\n", worker)
+			}
+			return nil
+		})
+	},
+}
+
+func withAdmin(cmd *cobra.Command, fn func(a *core.Admin) error) error {
+	c := loadConfig(cmd)
+	conn, err := connectZk(c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return fn(core.NewAdmin(conn, c.Namespace, zkACL(c)))
+}
+
+func connectZk(c *config.Config) (*zk.Conn, error) {
+	conn, _, err := zk.Connect(c.Zk.Servers, time.Duration(c.Zk.TimeoutMs)*time.Millisecond, zk.WithLogInfo(false))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Zk.User != "" {
+		err = conn.AddAuth("digest", []byte(fmt.Sprintf("%s:%s", c.Zk.User, c.Zk.Password)))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+	adminCmd.PersistentFlags().StringP(envarg, "e", "local.env", "Config file for the config")
+	adminCmd.AddCommand(pinCmd, unpinCmd, cordonCmd, uncordonCmd, listCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hextechpal/prio/engine/mysql"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd groups the commands managing the database schema of the mysql engine
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manages the database schema of the mysql engine",
+	Long: `It applies and reverts the migrations embedded in the mysql engine.
+Applied versions are tracked in the prio_schema_migrations table, the topics and jobs
+tables of a database set up by hand are recorded as applied without running them again`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applies all the pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMysql(cmd, func(ctx context.Context, e *mysql.Engine) error {
+			return e.Migrate(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [steps]",
+	Short: "Reverts the last applied migrations, one by default",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %s", args[0])
+			}
+			steps = n
+		}
+		return withMysql(cmd, func(ctx context.Context, e *mysql.Engine) error {
+			return e.Rollback(ctx, steps)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Lists the migrations and whether they are applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMysql(cmd, func(ctx context.Context, e *mysql.Engine) error {
+			statuses, err := e.MigrationStatus(ctx)
+			if err != nil {
+				return err
+			}
+			for _, st := range statuses {
+				state := "pending"
+				if st.Applied {
+					state = fmt.Sprintf("applied at %s", time.UnixMilli(st.AppliedAt).Format(time.RFC3339))
+				}
+				fmt.Printf("%06d\t%s\t%s\n", st.Version, st.Name, state)
+			}
+			return nil
+		})
+	},
+}
+
+// withMysql : Runs fn on the configured database, or on every shard database if shards are configured
+func withMysql(cmd *cobra.Command, fn func(ctx context.Context, e *mysql.Engine) error) error {
+	c := loadConfig(cmd)
+	if c.DB.Driver != "mysql" {
+		return fmt.Errorf("migrations are only supported for the mysql driver, got %s", c.DB.Driver)
+	}
+
+	configs := []mysql.Config{mysqlConfig(c)}
+	if len(c.DB.Shards) > 0 {
+		var err error
+		if configs, err = mysqlShardConfigs(c); err != nil {
+			return err
+		}
+	}
+
+	for _, mc := range configs {
+		if len(configs) > 1 {
+			fmt.Printf("shard %s:%d\n", mc.Host, mc.Port)
+		}
+		if err := runMysql(mc, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runMysql(mc mysql.Config, fn func(ctx context.Context, e *mysql.Engine) error) error {
+	e, err := mysql.NewEngine(mc)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	return fn(context.Background(), e)
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.PersistentFlags().StringP(envarg, "e", "local.env", "Config file for the config")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// version of the worker binary, overridden at build time with -ldflags "-X github.com/hextechpal/prio/app/cmd.version=..."
+var version = "dev"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "prio",
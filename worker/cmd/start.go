@@ -3,22 +3,35 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"github.com/go-zookeeper/zk"
 	"github.com/hextechpal/prio/app/internal"
 	"github.com/hextechpal/prio/app/internal/config"
 	"github.com/hextechpal/prio/app/internal/handler"
+	"github.com/hextechpal/prio/app/internal/metrics"
+	"github.com/hextechpal/prio/app/internal/rpc"
+	"github.com/hextechpal/prio/app/internal/tracing"
 	"github.com/hextechpal/prio/core"
 	"github.com/hextechpal/prio/core/api"
 	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/bolt"
+	"github.com/hextechpal/prio/engine/memory"
 	"github.com/hextechpal/prio/engine/mysql"
+	"github.com/hextechpal/prio/engine/postgres"
+	"github.com/hextechpal/prio/engine/redis"
+	"github.com/hextechpal/prio/engine/shard"
+	"github.com/hextechpal/prio/engine/sqlite"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"time"
 )
 
@@ -33,31 +46,49 @@ It adds a worker to the worker pool specified by the name space
 The topics are load balanced all the workers`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		path, _ := cmd.Flags().GetString(envarg)
-		err := godotenv.Load(path)
-		if err != nil {
-			panic("error parsing config file")
-		}
-
-		c, err := config.Load()
-		if err != nil {
-			panic("error loading config")
-		}
-		setupServer(c)
+		setupServer(loadConfig(cmd))
 	},
 }
 
+func loadConfig(cmd *cobra.Command) *config.Config {
+	path, _ := cmd.Flags().GetString(envarg)
+	err := godotenv.Load(path)
+	if err != nil {
+		panic("error parsing config file")
+	}
+
+	c, err := config.Load()
+	if err != nil {
+		panic("error loading config")
+	}
+	return c
+}
+
 func setupServer(c *config.Config) {
 	id := commons.GenerateUuid()
 	ctx, cancel := context.WithCancel(context.Background())
 
 	logger := initLogger(id, c)
-	w, err := initWorker(id, c, logger)
+	m := metrics.New()
+	t, err := tracing.New(tracing.Config{Exporter: c.Tracing.Exporter, File: c.Tracing.File})
 	if err != nil {
 		panic(err)
 	}
+	defer func() {
+		if err := t.Shutdown(context.Background()); err != nil {
+			logger.Error(err, "error flushing traces")
+		}
+	}()
+
+	w, err := initWorker(id, c, logger, m, t)
+	if err != nil {
+		panic(err)
+	}
+	m.RegisterWorker(w)
 
 	r := initRouter(c.Debug)
+	r.Use(t.Echo())
+	r.GET("/metrics", echo.WrapHandler(m.Handler()))
 	g := r.Group("v1")
 
 	h, err := handler.NewHandler(ctx, w, logger)
@@ -66,7 +97,36 @@ func setupServer(c *config.Config) {
 	}
 	h.Register(g)
 
+	gs, err := startGrpcServer(w, c, logger, cancel)
+	if err != nil {
+		panic(err)
+	}
 	startServer(ctx, r, c, logger, cancel)
+	if gs != nil {
+		gs.Stop()
+	}
+}
+
+// startGrpcServer : Serves the grpc api on PRIO_GRPC_PORT, it returns nil if the port is not set
+func startGrpcServer(w *core.Worker, c *config.Config, logger commons.Logger, cancel context.CancelFunc) (*grpc.Server, error) {
+	if c.GRPC.Port == 0 {
+		return nil, nil
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", c.Server.Host, c.GRPC.Port))
+	if err != nil {
+		return nil, err
+	}
+
+	gs := grpc.NewServer()
+	rpc.NewServer(w, logger).Register(gs)
+	go func() {
+		if err := gs.Serve(lis); err != nil {
+			cancel()
+			logger.Fatal("shutting down the grpc server, error=%v", err)
+		}
+	}()
+	return gs, nil
 }
 
 func startServer(ctx context.Context, r *echo.Echo, c *config.Config, logger commons.Logger, cancel context.CancelFunc) {
@@ -106,30 +166,142 @@ func initRouter(debug bool) *echo.Echo {
 	return e
 }
 
-func initWorker(id string, c *config.Config, logger commons.Logger) (*core.Worker, error) {
-	engine, err := initEngine(c)
+func initWorker(id string, c *config.Config, logger commons.Logger, m *metrics.Metrics, t *tracing.Tracing) (*core.Worker, error) {
+	engine, err := initEngine(c, logger)
 	if err != nil {
 		return nil, err
 	}
+	engine = api.Chain(engine, append([]api.Middleware{m.Middleware(), t.Middleware()}, engineMiddlewares(c, logger)...)...)
 	timeout := time.Duration(c.Zk.TimeoutMs) * time.Millisecond
-	w := core.NewWorker(
-		c.Zk.Servers,
-		engine,
+	opts := []core.Option{
 		core.WithID(id),
 		core.WithNamespace(c.Namespace),
 		core.WithTimeout(timeout),
-		core.WithLogger(logger))
+		core.WithLogger(logger),
+		core.WithMetadata(core.Metadata{
+			Addr:     fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port),
+			Version:  version,
+			Capacity: c.Member.Capacity,
+			Labels:   c.Member.Labels,
+		}),
+		core.WithLongPolling(core.LongPolling{PollInterval: c.Dequeue.PollInterval, MaxWait: c.Dequeue.MaxWait}),
+	}
+	opts = append(opts, zkAuthOptions(c)...)
+	opts = append(opts, retentionOptions(c)...)
+	w := core.NewWorker(c.Zk.Servers, engine, opts...)
 	return w, nil
 }
 
-func initEngine(c *config.Config) (api.Engine, error) {
-	return mysql.NewEngine(mysql.Config{
-		Host:     c.DB.Host,
-		Port:     c.DB.Port,
-		User:     c.DB.User,
-		Password: c.DB.Password,
-		DBName:   c.DB.Database,
-	})
+func engineMiddlewares(c *config.Config, logger commons.Logger) []api.Middleware {
+	var middlewares []api.Middleware
+	if c.Engine.Logging {
+		middlewares = append(middlewares, api.Logging(logger))
+	}
+	middlewares = append(middlewares, api.Validation(api.ValidationConfig{MaxPayloadBytes: c.Engine.MaxPayloadBytes}))
+	if c.Engine.RetryAttempts > 1 {
+		middlewares = append(middlewares, api.Retry(api.RetryConfig{
+			Attempts:  c.Engine.RetryAttempts,
+			Backoff:   c.Engine.RetryBackoff,
+			Retryable: retryable(c.DB.Driver),
+		}))
+	}
+	return middlewares
+}
+
+// retryable : Returns the transient error check of the engine, the sql engines add their deadlock and lock timeout errors
+func retryable(driver string) func(err error) bool {
+	switch driver {
+	case "mysql":
+		return mysql.IsTransient
+	case "postgres":
+		return postgres.IsTransient
+	case "sqlite":
+		return sqlite.IsTransient
+	default:
+		return api.IsTransient
+	}
+}
+
+func retentionOptions(c *config.Config) []core.Option {
+	if c.Retention.After == 0 && len(c.Retention.Topics) == 0 {
+		return nil
+	}
+
+	topics := make(map[string]core.Retention, len(c.Retention.Topics))
+	for topic, r := range c.Retention.Topics {
+		archive := c.Retention.Archive
+		if r.Archive != nil {
+			archive = *r.Archive
+		}
+		topics[topic] = core.Retention{After: r.After, Archive: archive}
+	}
+	return []core.Option{
+		core.WithRetention(core.RetentionPolicy{
+			Default:   core.Retention{After: c.Retention.After, Archive: c.Retention.Archive},
+			Topics:    topics,
+			BatchSize: c.Retention.BatchSize,
+		}),
+	}
+}
+
+func zkAuthOptions(c *config.Config) []core.Option {
+	if c.Zk.User == "" {
+		return nil
+	}
+	return []core.Option{
+		core.WithDigestAuth(c.Zk.User, c.Zk.Password),
+		core.WithACL(zkACL(c)),
+	}
+}
+
+func zkACL(c *config.Config) []zk.ACL {
+	if c.Zk.User == "" {
+		return zk.WorldACL(zk.PermAll)
+	}
+
+	acl := zk.AuthACL(zk.PermAll)
+	if c.Zk.WorldReadable {
+		acl = append(acl, zk.WorldACL(zk.PermRead)...)
+	}
+	return acl
+}
+
+func initEngine(c *config.Config, logger commons.Logger) (api.Engine, error) {
+	switch c.DB.Driver {
+	case "mysql":
+		opts := []mysql.Option{mysql.WithLogger(logger)}
+		if c.DB.SchemaCheck {
+			opts = append(opts, mysql.WithSchemaCheck())
+		}
+		if len(c.DB.Shards) > 0 {
+			return initMysqlShards(c, logger, opts)
+		}
+		return mysql.NewEngine(mysqlConfig(c), opts...)
+	case "postgres":
+		return postgres.NewEngine(postgres.Config{
+			Host:     c.DB.Host,
+			Port:     c.DB.Port,
+			User:     c.DB.User,
+			Password: c.DB.Password,
+			DBName:   c.DB.Database,
+		}, postgres.WithLogger(logger))
+	case "redis":
+		return redis.NewEngine(redis.Config{
+			Addr:     fmt.Sprintf("%s:%d", c.DB.Host, c.DB.Port),
+			Password: c.DB.Password,
+		}, redis.WithLogger(logger))
+	case "memory":
+		if c.DB.Path == "" {
+			return memory.NewEngine(memory.WithLogger(logger))
+		}
+		return memory.NewEngine(memory.WithLogger(logger), memory.WithWAL(memory.WALConfig{Dir: c.DB.Path}))
+	case "bolt":
+		return bolt.NewEngine(bolt.Config{Path: c.DB.Path}, bolt.WithLogger(logger))
+	case "sqlite":
+		return sqlite.NewEngine(sqlite.Config{Path: c.DB.Path}, sqlite.WithLogger(logger))
+	default:
+		return nil, fmt.Errorf("unsupported db driver %s", c.DB.Driver)
+	}
 }
 
 func initLogger(id string, c *config.Config) commons.Logger {
@@ -154,3 +326,71 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().StringP(envarg, "e", "local.env", "Config file for the config")
 }
+
+// initMysqlShards : Connects to every shard database and spreads the topics over them
+func initMysqlShards(c *config.Config, logger commons.Logger, opts []mysql.Option) (api.Engine, error) {
+	configs, err := mysqlShardConfigs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]api.Engine, 0, len(configs))
+	for _, mc := range configs {
+		e, err := mysql.NewEngine(mc, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("shard %s:%d: %w", mc.Host, mc.Port, err)
+		}
+		shards = append(shards, e)
+	}
+	return shard.NewEngine(shards, shard.WithTopicMap(c.DB.ShardTopics), shard.WithLogger(logger))
+}
+
+// mysqlShardConfigs : Returns the config of every shard database, they only differ from the main config by host and port
+func mysqlShardConfigs(c *config.Config) ([]mysql.Config, error) {
+	configs := make([]mysql.Config, 0, len(c.DB.Shards))
+	for _, addr := range c.DB.Shards {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shard port %s", addr)
+		}
+
+		mc := mysqlConfig(c)
+		mc.Host, mc.Port = host, int32(p)
+		configs = append(configs, mc)
+	}
+	return configs, nil
+}
+
+func mysqlConfig(c *config.Config) mysql.Config {
+	return mysql.Config{
+		Host:     c.DB.Host,
+		Port:     c.DB.Port,
+		User:     c.DB.User,
+		Password: c.DB.Password,
+		DBName:   c.DB.Database,
+
+		Timeout:      c.DB.Timeout,
+		ReadTimeout:  c.DB.ReadTimeout,
+		WriteTimeout: c.DB.WriteTimeout,
+		ParseTime:    c.DB.ParseTime,
+
+		Pool: mysql.PoolConfig{
+			MaxOpenConns:    c.DB.MaxOpenConns,
+			MaxIdleConns:    c.DB.MaxIdleConns,
+			ConnMaxLifetime: c.DB.ConnMaxLifetime,
+			ConnMaxIdleTime: c.DB.ConnMaxIdleTime,
+		},
+		TLS: mysql.TLSConfig{
+			Mode:       c.DB.TLSMode,
+			CACert:     c.DB.TLSCACert,
+			ClientCert: c.DB.TLSClientCert,
+			ClientKey:  c.DB.TLSClientKey,
+			ServerName: c.DB.TLSServerName,
+		},
+		Replicas: c.DB.Replicas,
+	}
+}
@@ -4,37 +4,88 @@ go 1.19
 
 require (
 	github.com/go-zookeeper/zk v1.0.3
+	github.com/gorilla/websocket v1.5.0
 	github.com/hextechpal/prio/core v0.0.0-20221125151452-105fca04192c
+	github.com/hextechpal/prio/engine/bolt v0.0.0-00010101000000-000000000000
 	github.com/hextechpal/prio/engine/memory v0.0.0-20221125151452-105fca04192c
 	github.com/hextechpal/prio/engine/mysql v0.0.0-20221125151452-105fca04192c
+	github.com/hextechpal/prio/engine/postgres v0.0.0-00010101000000-000000000000
+	github.com/hextechpal/prio/engine/redis v0.0.0-00010101000000-000000000000
+	github.com/hextechpal/prio/engine/shard v0.0.0-00010101000000-000000000000
+	github.com/hextechpal/prio/engine/sqlite v0.0.0-00010101000000-000000000000
+	github.com/hextechpal/prio/proto v0.0.0-00010101000000-000000000000
 	github.com/joho/godotenv v1.4.0
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/labstack/echo/v4 v4.9.1
 	github.com/labstack/gommon v0.4.0
+	github.com/prometheus/client_golang v1.14.0
 	github.com/rs/zerolog v1.28.0
 	github.com/spf13/cobra v1.6.0
+	go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho v0.37.0
+	go.opentelemetry.io/otel v1.11.2
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.11.2
+	go.opentelemetry.io/otel/sdk v1.11.2
+	go.opentelemetry.io/otel/trace v1.11.2
+	google.golang.org/grpc v1.51.0
 )
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/dustin/go-humanize v1.0.0 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-sql-driver/mysql v1.6.0 // indirect
 	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/jmoiron/sqlx v1.3.5 // indirect
+	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
+	github.com/lib/pq v1.10.7 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.16 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/client_model v0.3.0 // indirect
+	github.com/prometheus/common v0.37.0 // indirect
+	github.com/prometheus/procfs v0.8.0 // indirect
+	github.com/redis/go-redis/v9 v9.0.2 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.1 // indirect
-	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5 // indirect
-	golang.org/x/net v0.0.0-20211015210444-4f30a5c0130f // indirect
-	golang.org/x/sys v0.0.0-20221006211917-84dc82d7e875 // indirect
-	golang.org/x/text v0.3.7 // indirect
+	go.etcd.io/bbolt v1.3.7 // indirect
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
+	golang.org/x/mod v0.6.0-dev.0.20220419223038-86c51ed26bb4 // indirect
+	golang.org/x/net v0.0.0-20220722155237-a158d28d115b // indirect
+	golang.org/x/sys v0.4.0 // indirect
+	golang.org/x/text v0.4.0 // indirect
 	golang.org/x/time v0.0.0-20201208040808-7e3f01d25324 // indirect
+	golang.org/x/tools v0.1.12 // indirect
+	google.golang.org/genproto v0.0.0-20200825200019-8632dd797987 // indirect
+	google.golang.org/protobuf v1.28.1 // indirect
+	lukechampine.com/uint128 v1.2.0 // indirect
+	modernc.org/cc/v3 v3.40.0 // indirect
+	modernc.org/ccgo/v3 v3.16.13 // indirect
+	modernc.org/libc v1.22.3 // indirect
+	modernc.org/mathutil v1.5.0 // indirect
+	modernc.org/memory v1.5.0 // indirect
+	modernc.org/opt v0.1.3 // indirect
+	modernc.org/sqlite v1.21.0 // indirect
+	modernc.org/strutil v1.1.3 // indirect
+	modernc.org/token v1.0.1 // indirect
 )
 
-//replace (
-//	github.com/hextechpal/prio/core => ../core
-//	github.com/hextechpal/prio/engine/memory => ../engine/memory
-//	github.com/hextechpal/prio/engine/mysql => ../engine/mysql
-//)
+replace (
+	github.com/hextechpal/prio/core => ../core
+	github.com/hextechpal/prio/engine/bolt => ../engine/bolt
+	github.com/hextechpal/prio/engine/memory => ../engine/memory
+	github.com/hextechpal/prio/engine/mysql => ../engine/mysql
+	github.com/hextechpal/prio/engine/postgres => ../engine/postgres
+	github.com/hextechpal/prio/engine/redis => ../engine/redis
+	github.com/hextechpal/prio/engine/shard => ../engine/shard
+	github.com/hextechpal/prio/engine/sqlite => ../engine/sqlite
+	github.com/hextechpal/prio/proto => ../proto
+)
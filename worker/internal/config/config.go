@@ -1,6 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -14,17 +18,110 @@ type Config struct {
 		Port int32  `envconfig:"PRIO_SERVER_PORT"`
 	}
 
+	GRPC struct {
+		Port int32 `envconfig:"PRIO_GRPC_PORT"` // grpc api port on the server host, the grpc api is disabled if zero
+	}
+
+	Member struct {
+		Capacity int               `envconfig:"PRIO_MEMBER_CAPACITY"` // capacity hint advertised to the other workers
+		Labels   map[string]string `envconfig:"PRIO_MEMBER_LABELS"`   // labels used for topic placement, format key1:value1,key2:value2
+	}
+
 	DB struct {
-		Host     string `envconfig:"PRIO_DB_HOST"`     // Only sql is supported for now
-		Port     int32  `envconfig:"PRIO_DB_PORT"`     // Only sql is supported for now
-		User     string `envconfig:"PRIO_DB_USER"`     // Only sql is supported for now
-		Password string `envconfig:"PRIO_DB_PASSWORD"` // Only sql is supported for now
-		Database string `envconfig:"PRIO_DB_DATABASE"` // Only sql is supported for now
+		Driver string `envconfig:"PRIO_DB_DRIVER" default:"mysql"` // mysql, postgres, redis, sqlite, bolt or memory
+		Path   string `envconfig:"PRIO_DB_PATH"`                   // database file for sqlite and bolt, wal directory for memory
+
+		Host     string `envconfig:"PRIO_DB_HOST"`     // mysql, postgres and redis only
+		Port     int32  `envconfig:"PRIO_DB_PORT"`     // mysql, postgres and redis only
+		User     string `envconfig:"PRIO_DB_USER"`     // mysql and postgres only
+		Password string `envconfig:"PRIO_DB_PASSWORD"` // mysql, postgres and redis only
+		Database string `envconfig:"PRIO_DB_DATABASE"` // mysql and postgres only
+
+		SchemaCheck bool `envconfig:"PRIO_DB_SCHEMA_CHECK"` // mysql only, refuse to start unless all the migrations are applied
+
+		Timeout      time.Duration `envconfig:"PRIO_DB_TIMEOUT"`       // mysql only, dial timeout
+		ReadTimeout  time.Duration `envconfig:"PRIO_DB_READ_TIMEOUT"`  // mysql only, I/O read timeout
+		WriteTimeout time.Duration `envconfig:"PRIO_DB_WRITE_TIMEOUT"` // mysql only, I/O write timeout
+		ParseTime    bool          `envconfig:"PRIO_DB_PARSE_TIME"`    // mysql only
+
+		MaxOpenConns    int           `envconfig:"PRIO_DB_MAX_OPEN_CONNS"`     // mysql only, zero keeps the default
+		MaxIdleConns    int           `envconfig:"PRIO_DB_MAX_IDLE_CONNS"`     // mysql only, zero keeps the default
+		ConnMaxLifetime time.Duration `envconfig:"PRIO_DB_CONN_MAX_LIFETIME"`  // mysql only, zero keeps the default
+		ConnMaxIdleTime time.Duration `envconfig:"PRIO_DB_CONN_MAX_IDLE_TIME"` // mysql only, zero keeps the default
+
+		TLSMode       string `envconfig:"PRIO_DB_TLS_MODE"`        // mysql only, true, false, skip-verify or preferred
+		TLSCACert     string `envconfig:"PRIO_DB_TLS_CA_CERT"`     // mysql only, path of the CA certificate
+		TLSClientCert string `envconfig:"PRIO_DB_TLS_CLIENT_CERT"` // mysql only, path of the client certificate
+		TLSClientKey  string `envconfig:"PRIO_DB_TLS_CLIENT_KEY"`  // mysql only, path of the client key
+		TLSServerName string `envconfig:"PRIO_DB_TLS_SERVER_NAME"` // mysql only, server name to verify the certificate against
+
+		Replicas []string `envconfig:"PRIO_DB_REPLICAS"` // mysql only, host:port of the read replicas
+
+		Shards      []string       `envconfig:"PRIO_DB_SHARDS"`       // mysql only, host:port of the shard databases, replaces host and port if set
+		ShardTopics map[string]int `envconfig:"PRIO_DB_SHARD_TOPICS"` // mysql only, topic to shard index, format topic1:0,topic2:1, other topics are hashed
+	}
+
+	Engine struct {
+		Logging         bool          `envconfig:"PRIO_ENGINE_LOGGING"`           // log every engine call
+		MaxPayloadBytes int           `envconfig:"PRIO_ENGINE_MAX_PAYLOAD_BYTES"` // reject larger payloads, zero disables the limit
+		RetryAttempts   int           `envconfig:"PRIO_ENGINE_RETRY_ATTEMPTS"`    // attempts on transient engine errors (connection failures, deadlocks, lock timeouts), retries are disabled below 2
+		RetryBackoff    time.Duration `envconfig:"PRIO_ENGINE_RETRY_BACKOFF"`     // delay before the first retry, doubled on every retry
+	}
+
+	Dequeue struct {
+		PollInterval time.Duration `envconfig:"PRIO_DEQUEUE_POLL_INTERVAL"` // waiting dequeue calls re-check the engine at this interval, zero keeps the default of 1s
+		MaxWait      time.Duration `envconfig:"PRIO_DEQUEUE_MAX_WAIT"`      // upper bound of the dequeue wait timeout, zero keeps the default of 30s
+	}
+
+	Tracing struct {
+		Exporter string `envconfig:"PRIO_TRACING_EXPORTER"` // stdout or file, tracing is disabled if empty
+		File     string `envconfig:"PRIO_TRACING_FILE"`     // output of the file exporter
+	}
+
+	Retention struct {
+		After     time.Duration             `envconfig:"PRIO_RETENTION_AFTER"`      // completed jobs older than this are purged, disabled if zero
+		Archive   bool                      `envconfig:"PRIO_RETENTION_ARCHIVE"`    // archive purged jobs instead of deleting them
+		Topics    map[string]TopicRetention `envconfig:"PRIO_RETENTION_TOPICS"`     // per topic retention, format topic1:24h/archive,topic2:1h/delete,topic3:1h
+		BatchSize int                       `envconfig:"PRIO_RETENTION_BATCH_SIZE"` // max jobs purged per topic on every maintenance run
 	}
 
 	Zk struct {
 		Servers   []string `envconfig:"PRIO_ZK_SERVERS"`    // list of zookeeper servers
 		TimeoutMs int32    `envconfig:"PRIO_ZK_TIMEOUT_MS"` // timeout in millisecond
+
+		User          string `envconfig:"PRIO_ZK_USER"`           // digest auth user, auth is disabled if empty
+		Password      string `envconfig:"PRIO_ZK_PASSWORD"`       // digest auth password
+		WorldReadable bool   `envconfig:"PRIO_ZK_WORLD_READABLE"` // grants read access on prio znodes to everyone when auth is enabled
+	}
+}
+
+// TopicRetention : Retention of a topic, a duration with an optional /archive or /delete suffix overriding PRIO_RETENTION_ARCHIVE
+type TopicRetention struct {
+	After   time.Duration
+	Archive *bool // Archive: nil uses PRIO_RETENTION_ARCHIVE
+}
+
+// Decode : Implements envconfig.Decoder
+func (r *TopicRetention) Decode(value string) error {
+	after, mode, found := strings.Cut(value, "/")
+	d, err := time.ParseDuration(after)
+	if err != nil {
+		return err
+	}
+
+	r.After = d
+	r.Archive = nil
+	if !found {
+		return nil
+	}
+
+	switch mode {
+	case "archive", "delete":
+		archive := mode == "archive"
+		r.Archive = &archive
+		return nil
+	default:
+		return fmt.Errorf("invalid retention mode %q, want archive or delete", mode)
 	}
 }
 
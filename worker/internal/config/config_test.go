@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoad_RetentionTopics(t *testing.T) {
+	t.Setenv("PRIO_RETENTION_TOPICS", "t1:24h/archive,t2:1h/delete,t3:30m")
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load() err=%v", err)
+	}
+
+	tests := []struct {
+		topic   string
+		after   time.Duration
+		archive *bool
+	}{
+		{topic: "t1", after: 24 * time.Hour, archive: boolPtr(true)},
+		{topic: "t2", after: time.Hour, archive: boolPtr(false)},
+		{topic: "t3", after: 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		got := c.Retention.Topics[tt.topic]
+		if got.After != tt.after || (got.Archive == nil) != (tt.archive == nil) || (got.Archive != nil && *got.Archive != *tt.archive) {
+			t.Errorf("Retention.Topics[%s] got = %v, want after=%s archive=%v", tt.topic, got, tt.after, tt.archive)
+		}
+	}
+}
+
+func TestTopicRetention_Decode(t *testing.T) {
+	for _, value := range []string{"", "1h/keep", "forever/archive"} {
+		var r TopicRetention
+		if err := r.Decode(value); err == nil {
+			t.Errorf("Decode(%q) got = %v, want an error", value, r)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hextechpal/prio/core"
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	pinRequest struct {
+		Worker string
+	}
+
+	workerResponse struct {
+		core.Member
+		Cordoned bool
+	}
+)
+
+func (h *Handler) workers() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		members, err := h.w.Members()
+		if err != nil {
+			return h.error(c, err)
+		}
+
+		cordons, err := h.w.Admin().Cordons()
+		if err != nil {
+			return h.error(c, err)
+		}
+
+		res := make([]workerResponse, len(members))
+		for i, m := range members {
+			res[i] = workerResponse{Member: m, Cordoned: cordons[m.ID]}
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *Handler) cordon() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := h.w.Admin().Cordon(c.Param("worker")); err != nil {
+			return h.error(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) uncordon() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := h.w.Admin().Uncordon(c.Param("worker")); err != nil {
+			return h.error(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) pins() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		pins, err := h.w.Admin().Pins()
+		if err != nil {
+			return h.error(c, err)
+		}
+		return c.JSON(http.StatusOK, pins)
+	}
+}
+
+func (h *Handler) pin() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := pinRequest{}
+		if err := c.Bind(&req); err != nil {
+			return h.badRequest(c, err)
+		}
+		if req.Worker == "" {
+			return h.badRequest(c, errors.New("worker is required"))
+		}
+		if err := h.w.Admin().Pin(c.Param("topic"), req.Worker); err != nil {
+			return h.error(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) unpin() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := h.w.Admin().Unpin(c.Param("topic")); err != nil {
+			return h.error(c, err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/labstack/echo/v4"
+)
+
+// errorResponse : Body of the failed requests, Code is the stable api.ErrorCode of the error
+type errorResponse struct {
+	Code    string
+	Message string
+}
+
+// error : Writes the error with its api.HTTPStatus and api.ErrorCode, the unexpected failures are logged
+func (h *Handler) error(c echo.Context, err error) error {
+	status := api.HTTPStatus(err)
+	if status == http.StatusServiceUnavailable {
+		h.logger.Error(err, "request failed method=%s path=%s", c.Request().Method, c.Path())
+	}
+	return c.JSON(status, errorResponse{Code: api.ErrorCode(err), Message: err.Error()})
+}
+
+// badRequest : Writes a malformed request error, e.g. a body or a parameter which can not be parsed
+func (h *Handler) badRequest(c echo.Context, err error) error {
+	return h.error(c, fmt.Errorf("%w: %s", api.ErrorInvalidRequest, err.Error()))
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/hextechpal/prio/core/commons"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type Handler struct {
@@ -27,17 +29,29 @@ func (h *Handler) Register(g *echo.Group) {
 	g.POST("/enqueue", h.enqueue())
 	g.GET("/dequeue", h.dequeue())
 	g.POST("/ack", h.ack())
+	g.POST("/extend", h.extendLease())
+	g.POST("/nack", h.nack())
+	g.GET("/jobs/:id", h.getJob())
+	g.GET("/stream", h.stream())
+
+	g.GET("/workers", h.workers())
+	g.PUT("/workers/:worker/cordon", h.cordon())
+	g.DELETE("/workers/:worker/cordon", h.uncordon())
+
+	g.GET("/pins", h.pins())
+	g.PUT("/topics/:topic/pin", h.pin())
+	g.DELETE("/topics/:topic/pin", h.unpin())
 }
 
 func (h *Handler) enqueue() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		req := api.EnqueueRequest{}
 		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.badRequest(c, err)
 		}
 		res, err := h.w.Enqueue(c.Request().Context(), req)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.error(c, err)
 		}
 		return c.JSON(http.StatusOK, res)
 	}
@@ -47,11 +61,37 @@ func (h *Handler) dequeue() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		req := api.DequeueRequest{}
 		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.badRequest(c, err)
+		}
+		// wait: long polling timeout as a duration, e.g. ?wait=10s
+		if wait := c.QueryParam("wait"); wait != "" {
+			d, err := time.ParseDuration(wait)
+			if err != nil {
+				return h.badRequest(c, err)
+			}
+			req.WaitTimeout = d
 		}
 		res, err := h.w.Dequeue(c.Request().Context(), req)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.error(c, err)
+		}
+		// the topic has no pending job, or none arrived within the wait
+		if res.JobId == 0 {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *Handler) getJob() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return h.badRequest(c, err)
+		}
+		res, err := h.w.GetJob(c.Request().Context(), api.GetJobRequest{JobId: id})
+		if err != nil {
+			return h.error(c, err)
 		}
 		return c.JSON(http.StatusOK, res)
 	}
@@ -61,11 +101,11 @@ func (h *Handler) registerTopic() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		req := api.RegisterTopicRequest{}
 		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.badRequest(c, err)
 		}
 		res, err := h.w.RegisterTopic(c.Request().Context(), req)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.error(c, err)
 		}
 		return c.JSON(http.StatusOK, res)
 	}
@@ -75,11 +115,39 @@ func (h *Handler) ack() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		req := api.AckRequest{}
 		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.badRequest(c, err)
 		}
 		res, err := h.w.Ack(c.Request().Context(), req)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, err)
+			return h.error(c, err)
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *Handler) extendLease() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := api.LeaseRequest{}
+		if err := c.Bind(&req); err != nil {
+			return h.badRequest(c, err)
+		}
+		res, err := h.w.ExtendLease(c.Request().Context(), req)
+		if err != nil {
+			return h.error(c, err)
+		}
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *Handler) nack() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := api.LeaseRequest{}
+		if err := c.Bind(&req); err != nil {
+			return h.badRequest(c, err)
+		}
+		res, err := h.w.Nack(c.Request().Context(), req)
+		if err != nil {
+			return h.error(c, err)
 		}
 		return c.JSON(http.StatusOK, res)
 	}
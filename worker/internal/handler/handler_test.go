@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/memory"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandler_errors(t *testing.T) {
+	engine, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	w := core.NewWorker(nil, engine)
+	ctx := context.Background()
+	for _, topic := range []string{"t1", "t2", "t3"} {
+		if _, err = w.RegisterTopic(ctx, api.RegisterTopicRequest{Name: topic}); err != nil {
+			t.Fatalf("RegisterTopic() err=%v", err)
+		}
+	}
+
+	e := echo.New()
+	h := &Handler{w: w, logger: &commons.DefaultLogger{}}
+	h.Register(e.Group("v1"))
+
+	// job 1 is acked, job 2 is claimed by c1 then re-queued, job 3 is claimed by c1 and t3 is empty
+	_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 2})
+	_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t2", Priority: 1})
+	_, _ = w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	_, _ = w.Ack(ctx, api.AckRequest{JobId: 1, Consumer: "c1"})
+	_, _ = w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	_, _ = w.ReQueue(ctx, api.RequeueRequest{Topic: "t1", RequeueTs: time.Now().Add(time.Minute).UnixMilli()})
+	_, _ = w.Dequeue(ctx, api.DequeueRequest{Topic: "t2", Consumer: "c1"})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{name: "empty dequeue", method: http.MethodGet, target: "/v1/dequeue", body: `{"Topic":"t3","Consumer":"c1"}`, status: http.StatusNoContent},
+		{name: "empty dequeue with wait", method: http.MethodGet, target: "/v1/dequeue?wait=10ms", body: `{"Topic":"t3","Consumer":"c1"}`, status: http.StatusNoContent},
+		{name: "bad wait", method: http.MethodGet, target: "/v1/dequeue?wait=soon", body: `{"Topic":"t1","Consumer":"c1"}`, status: http.StatusBadRequest, code: "INVALID_REQUEST"},
+		{name: "bad body", method: http.MethodPost, target: "/v1/ack", body: `{"JobId":"one"}`, status: http.StatusBadRequest, code: "INVALID_REQUEST"},
+		{name: "bad job id", method: http.MethodGet, target: "/v1/jobs/one", status: http.StatusBadRequest, code: "INVALID_REQUEST"},
+		{name: "job not present", method: http.MethodGet, target: "/v1/jobs/42", status: http.StatusNotFound, code: "JOB_NOT_PRESENT"},
+		{name: "already acked", method: http.MethodPost, target: "/v1/ack", body: `{"JobId":1,"Consumer":"c1"}`, status: http.StatusConflict, code: "ALREADY_ACKED"},
+		{name: "wrong consumer", method: http.MethodPost, target: "/v1/ack", body: `{"JobId":3,"Consumer":"c2"}`, status: http.StatusConflict, code: "WRONG_CONSUMER"},
+		{name: "lease exceeded", method: http.MethodPost, target: "/v1/nack", body: `{"JobId":2,"Consumer":"c1"}`, status: http.StatusGone, code: "LEASE_EXCEEDED"},
+		{name: "ack", method: http.MethodPost, target: "/v1/ack", body: `{"JobId":3,"Consumer":"c1"}`, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status got = %d, want %d, body %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.code == "" {
+				return
+			}
+			var res errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.Code != tt.code || res.Message == "" {
+				t.Errorf("body got = %s, err=%v, want code %s", rec.Body.String(), err, tt.code)
+			}
+		})
+	}
+}
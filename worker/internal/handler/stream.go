@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxStreamCredits = 1000             // maxStreamCredits: upper bound of the credits a stream can hold
+	streamWait       = 30 * time.Second // streamWait: wait timeout of the dequeue calls of a stream
+)
+
+// Stream messages, the client sends "ack" and "credit" messages and the server sends "job", "ack" and "error" messages
+const (
+	msgJob    = "job"
+	msgAck    = "ack"
+	msgCredit = "credit"
+	msgError  = "error"
+)
+
+type (
+	// streamRequest : Message sent by the client, ack a job or grant credits
+	streamRequest struct {
+		Type    string
+		JobId   int64
+		Credits int
+	}
+
+	// streamResponse : Message sent by the server, a job, the outcome of an ack or an error
+	streamResponse struct {
+		Type  string
+		Job   *api.DequeueResponse `json:",omitempty"`
+		JobId int64                `json:",omitempty"`
+		Acked bool                 `json:",omitempty"`
+		Code  string               `json:",omitempty"` // Code: api.ErrorCode of the error
+		Error string               `json:",omitempty"`
+	}
+
+	// stream : A websocket consuming a topic. Every delivered job takes a credit and every acked job gives it back,
+	// so the initial credits bound the jobs in flight. The client can grant more credits with a credit message
+	stream struct {
+		h        *Handler
+		conn     *websocket.Conn
+		topic    string
+		consumer string
+
+		wmu     sync.Mutex    // wmu: serializes the writes, a websocket supports a single writer
+		credits chan struct{} // credits: one token per job the server may deliver
+
+		mu       sync.Mutex
+		inflight map[int64]struct{} // inflight: jobs delivered on the stream and not acked yet, only their acks give a credit back
+	}
+)
+
+// upgrader : Accepts any origin like the CORS policy of the http api, the api carries no cookies or browser credentials
+// so a cross site page can do nothing over the stream it could not do with a plain request
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// stream : Delivers the jobs of a topic over a websocket, GET /v1/stream?topic=t1&consumer=c1&credits=10
+func (h *Handler) stream() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		topic, consumer := c.QueryParam("topic"), c.QueryParam("consumer")
+		if topic == "" || consumer == "" {
+			return h.badRequest(c, errors.New("topic and consumer are required"))
+		}
+
+		credits := 1
+		if v := c.QueryParam("credits"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > maxStreamCredits {
+				return h.badRequest(c, errors.New("credits must be between 1 and 1000"))
+			}
+			credits = n
+		}
+
+		conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = conn.Close() }()
+
+		s := &stream{
+			h:        h,
+			conn:     conn,
+			topic:    topic,
+			consumer: consumer,
+			credits:  make(chan struct{}, maxStreamCredits),
+			inflight: make(map[int64]struct{}),
+		}
+		s.grant(credits)
+		s.run(c.Request().Context())
+		return nil
+	}
+}
+
+// run : Delivers the jobs until the client goes away, the jobs delivered but not acked are re-queued once their lease expires
+func (s *stream) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go s.deliver(ctx)
+
+	for {
+		var req streamRequest
+		if err := s.conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		switch req.Type {
+		case msgAck:
+			s.ack(ctx, req.JobId)
+		case msgCredit:
+			s.grant(req.Credits)
+		default:
+			_ = s.write(streamResponse{Type: msgError, Code: api.ErrorCode(api.ErrorInvalidRequest), Error: "unknown message type " + req.Type})
+		}
+	}
+}
+
+// deliver : Waits for a credit, then for a job and sends it. The connection is closed on return to stop the reads
+func (s *stream) deliver(ctx context.Context) {
+	defer func() { _ = s.conn.Close() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.credits:
+		}
+
+		res, err := s.dequeue(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				_ = s.write(streamResponse{Type: msgError, Code: api.ErrorCode(err), Error: err.Error()})
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.inflight[res.JobId] = struct{}{}
+		s.mu.Unlock()
+		if err = s.write(streamResponse{Type: msgJob, Job: &res}); err != nil {
+			return
+		}
+	}
+}
+
+// dequeue : Long polls the topic until a job is claimed
+func (s *stream) dequeue(ctx context.Context) (api.DequeueResponse, error) {
+	for {
+		res, err := s.h.w.Dequeue(ctx, api.DequeueRequest{Topic: s.topic, Consumer: s.consumer, WaitTimeout: streamWait})
+		if err != nil || res.JobId != 0 {
+			return res, err
+		}
+	}
+}
+
+// ack : Acks the job, the credit comes back only for a job delivered on this stream so that acking jobs
+// dequeued elsewhere cannot raise the jobs in flight above the credits granted
+func (s *stream) ack(ctx context.Context, jobId int64) {
+	res, err := s.h.w.Ack(ctx, api.AckRequest{JobId: jobId, Consumer: s.consumer})
+	if err != nil {
+		// a job whose lease expired is not in flight anymore
+		if errors.Is(err, api.ErrorLeaseExceeded) {
+			s.settle(jobId)
+		}
+		_ = s.write(streamResponse{Type: msgError, JobId: jobId, Code: api.ErrorCode(err), Error: err.Error()})
+		return
+	}
+	_ = s.write(streamResponse{Type: msgAck, JobId: jobId, Acked: res.Acked})
+	s.settle(jobId)
+}
+
+// settle : Takes the job out of flight and gives its credit back if it was delivered on the stream
+func (s *stream) settle(jobId int64) {
+	s.mu.Lock()
+	_, ok := s.inflight[jobId]
+	delete(s.inflight, jobId)
+	s.mu.Unlock()
+	if ok {
+		s.grant(1)
+	}
+}
+
+// grant : Adds credits, the credits above maxStreamCredits are dropped
+func (s *stream) grant(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case s.credits <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+func (s *stream) write(res streamResponse) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.conn.WriteJSON(res)
+}
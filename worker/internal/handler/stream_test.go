@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hextechpal/prio/core"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/engine/memory"
+	"github.com/labstack/echo/v4"
+)
+
+func newStreamServer(t *testing.T) (*core.Worker, string) {
+	t.Helper()
+	engine, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	w := core.NewWorker(nil, engine)
+	if _, err = w.RegisterTopic(context.Background(), api.RegisterTopicRequest{Name: "t1"}); err != nil {
+		t.Fatalf("RegisterTopic() err=%v", err)
+	}
+
+	e := echo.New()
+	h := &Handler{w: w}
+	h.Register(e.Group("v1"))
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return w, "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/stream"
+}
+
+func readStream(t *testing.T, conn *websocket.Conn) streamResponse {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var res streamResponse
+	if err := conn.ReadJSON(&res); err != nil {
+		t.Fatalf("ReadJSON() err=%v", err)
+	}
+	return res
+}
+
+func TestHandler_stream(t *testing.T) {
+	w, url := newStreamServer(t)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?topic=t1&consumer=c1&credits=2", nil)
+	if err != nil {
+		t.Fatalf("Dial() err=%v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	first, second := readStream(t, conn), readStream(t, conn)
+	if first.Type != msgJob || second.Type != msgJob {
+		t.Fatalf("got messages %s and %s, want two jobs", first.Type, second.Type)
+	}
+
+	// no credit left, the third job stays pending
+	time.Sleep(200 * time.Millisecond)
+	if count, _ := w.Engine.(api.Inspector).PendingCount(ctx, "t1"); count != 1 {
+		t.Fatalf("got %d pending jobs without credit, want 1", count)
+	}
+
+	if err = conn.WriteJSON(streamRequest{Type: msgAck, JobId: first.Job.JobId}); err != nil {
+		t.Fatalf("WriteJSON() err=%v", err)
+	}
+	if res := readStream(t, conn); res.Type != msgAck || res.JobId != first.Job.JobId || !res.Acked {
+		t.Errorf("got %+v, want the ack of job %d", res, first.Job.JobId)
+	}
+
+	// the credit given back by the ack delivers the third job
+	if res := readStream(t, conn); res.Type != msgJob {
+		t.Errorf("got message %s after the ack, want a job", res.Type)
+	}
+
+	if err = conn.WriteJSON(streamRequest{Type: msgAck, JobId: first.Job.JobId}); err != nil {
+		t.Fatalf("WriteJSON() err=%v", err)
+	}
+	if res := readStream(t, conn); res.Type != msgError || res.JobId != first.Job.JobId || res.Code != "ALREADY_ACKED" {
+		t.Errorf("got %+v, want an error for the second ack of job %d", res, first.Job.JobId)
+	}
+
+	// a granted credit delivers the job enqueued later
+	_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	if err = conn.WriteJSON(streamRequest{Type: msgCredit, Credits: 1}); err != nil {
+		t.Fatalf("WriteJSON() err=%v", err)
+	}
+	if res := readStream(t, conn); res.Type != msgJob {
+		t.Errorf("got message %s after the credit, want a job", res.Type)
+	}
+}
+
+func TestHandler_stream_AckForeignJob(t *testing.T) {
+	w, url := newStreamServer(t)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	}
+	foreign, _ := w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?topic=t1&consumer=c1&credits=1", nil)
+	if err != nil {
+		t.Fatalf("Dial() err=%v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if res := readStream(t, conn); res.Type != msgJob {
+		t.Fatalf("got message %s, want a job", res.Type)
+	}
+
+	// the job was not delivered on the stream, its ack gives no credit
+	if err = conn.WriteJSON(streamRequest{Type: msgAck, JobId: foreign.JobId}); err != nil {
+		t.Fatalf("WriteJSON() err=%v", err)
+	}
+	if res := readStream(t, conn); res.Type != msgAck || res.JobId != foreign.JobId {
+		t.Errorf("got %+v, want the ack of job %d", res, foreign.JobId)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if count, _ := w.Engine.(api.Inspector).PendingCount(ctx, "t1"); count != 1 {
+		t.Errorf("got %d pending jobs after acking a foreign job, want 1", count)
+	}
+}
+
+func TestHandler_stream_BadRequest(t *testing.T) {
+	_, url := newStreamServer(t)
+	for _, query := range []string{"?consumer=c1", "?topic=t1", "?topic=t1&consumer=c1&credits=0"} {
+		if _, res, err := websocket.DefaultDialer.Dial(url+query, nil); err == nil || res.StatusCode != 400 {
+			t.Errorf("Dial(%s) should fail with 400", query)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+)
+
+type metricsEngine struct {
+	api.Engine
+	m *Metrics
+}
+
+// Middleware : Counts and times every engine call, re-queued jobs are counted as lease expirations
+func (m *Metrics) Middleware() api.Middleware {
+	return func(e api.Engine) api.Engine {
+		return &metricsEngine{Engine: e, m: m}
+	}
+}
+
+func (e *metricsEngine) GetTopics(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	topics, err := e.Engine.GetTopics(ctx)
+	e.m.observe("get_topics", "", start, err, false)
+	return topics, err
+}
+
+func (e *metricsEngine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.RegisterTopic(ctx, req)
+	e.m.observe("register_topic", req.Name, start, err, false)
+	return res, err
+}
+
+func (e *metricsEngine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.Enqueue(ctx, req)
+	e.m.observe("enqueue", req.Topic, start, err, false)
+	return res, err
+}
+
+func (e *metricsEngine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.Dequeue(ctx, req)
+	e.m.observe("dequeue", req.Topic, start, err, res.JobId == 0)
+	return res, err
+}
+
+func (e *metricsEngine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.GetJob(ctx, req)
+	e.m.observe("get_job", res.Topic, start, err, false)
+	return res, err
+}
+
+// Ack : Observed without a topic, the request only carries the job id and looking the job up would double the cost of every ack
+func (e *metricsEngine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.Ack(ctx, req)
+	e.m.observe("ack", "", start, err, false)
+	return res, err
+}
+
+func (e *metricsEngine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	start := time.Now()
+	res, err := e.Engine.ReQueue(ctx, req)
+	e.m.observe("requeue", req.Topic, start, err, false)
+	if err == nil && res.Count > 0 {
+		e.m.leaseExpirations.WithLabelValues(req.Topic).Add(float64(res.Count))
+	}
+	return res, err
+}
+
+func (e *metricsEngine) Purge(ctx context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	p, ok := e.Engine.(api.Purger)
+	if !ok {
+		return api.PurgeResponse{}, api.ErrorNotSupported
+	}
+	start := time.Now()
+	res, err := p.Purge(ctx, req)
+	e.m.observe("purge", req.Topic, start, err, false)
+	return res, err
+}
+
+func (e *metricsEngine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	i, ok := e.Engine.(api.Inspector)
+	if !ok {
+		return 0, api.ErrorNotSupported
+	}
+	return i.PendingCount(ctx, topic)
+}
+
+func (e *metricsEngine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := e.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	start := time.Now()
+	res, err := l.ExtendLease(ctx, req)
+	e.m.observe("extend_lease", "", start, err, false)
+	return res, err
+}
+
+func (e *metricsEngine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := e.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	start := time.Now()
+	res, err := l.Nack(ctx, req)
+	e.m.observe("nack", "", start, err, false)
+	return res, err
+}
+
+// WaitForJob : Not observed, the call blocks until a job is enqueued
+func (e *metricsEngine) WaitForJob(ctx context.Context, topic string) error {
+	n, ok := e.Engine.(api.Notifier)
+	if !ok {
+		return api.ErrorNotSupported
+	}
+	return n.WaitForJob(ctx, topic)
+}
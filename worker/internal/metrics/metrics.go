@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/core"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "prio"
+
+// outcomes maps the engine contract errors to the outcome label
+var outcomes = []struct {
+	err     error
+	outcome string
+}{
+	{api.ErrorJobNotAcquired, "not_acquired"},
+	{api.ErrorJobNotPresent, "not_present"},
+	{api.ErrorAlreadyAcked, "already_acked"},
+	{api.ErrorWrongConsumer, "wrong_consumer"},
+	{api.ErrorLeaseExceeded, "lease_exceeded"},
+	{api.ErrorInvalidRequest, "invalid"},
+}
+
+// Metrics : Prometheus metrics of a worker and its engine
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests         *prometheus.CounterVec
+	duration         *prometheus.HistogramVec
+	leaseExpirations *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "engine_requests_total",
+			Help:      "Engine calls by operation, topic and outcome. Acks, lease extensions and nacks carry no topic",
+		}, []string{"op", "topic", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "engine_request_duration_seconds",
+			Help:      "Duration of the engine calls by operation and topic",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"op", "topic"}),
+		leaseExpirations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lease_expirations_total",
+			Help:      "Claimed jobs moved back to pending as they were not acked in time",
+		}, []string{"topic"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.leaseExpirations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler : Serves the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterWorker : Exposes the leader status, re-balance count, zookeeper session state and queue depths of the worker
+func (m *Metrics) RegisterWorker(w *core.Worker) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "leader",
+			Help:      "1 if the worker is the leader of its namespace",
+		}, func() float64 {
+			return boolToFloat(w.IsLeader())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rebalances_total",
+			Help:      "Topic re-balances performed by the worker as the leader",
+		}, func() float64 {
+			return float64(w.Rebalances())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "zk_connected",
+			Help:      "1 if the worker has a zookeeper session",
+		}, func() float64 {
+			return boolToFloat(w.ZkState() == zk.StateHasSession)
+		}),
+		&depthCollector{
+			w: w,
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "", "queue_depth"),
+				"Pending jobs by topic, only reported by the engines implementing api.Inspector",
+				[]string{"topic"}, nil,
+			),
+		},
+	)
+}
+
+func (m *Metrics) observe(op, topic string, start time.Time, err error, empty bool) {
+	m.duration.WithLabelValues(op, topic).Observe(time.Since(start).Seconds())
+	m.requests.WithLabelValues(op, topic, outcome(err, empty)).Inc()
+}
+
+func outcome(err error, empty bool) string {
+	if err == nil {
+		if empty {
+			return "empty"
+		}
+		return "ok"
+	}
+	for _, o := range outcomes {
+		if errors.Is(err, o.err) {
+			return o.outcome
+		}
+	}
+	return "error"
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// depthCollector : Reads the pending counts of all the topics on every scrape
+type depthCollector struct {
+	w    *core.Worker
+	desc *prometheus.Desc
+}
+
+func (d *depthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- d.desc
+}
+
+func (d *depthCollector) Collect(ch chan<- prometheus.Metric) {
+	inspector, ok := d.w.Engine.(api.Inspector)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	topics, err := d.w.GetTopics(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(d.desc, err)
+		return
+	}
+
+	for _, topic := range topics {
+		count, err := inspector.PendingCount(ctx, topic)
+		if errors.Is(err, api.ErrorNotSupported) {
+			return
+		}
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(d.desc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(d.desc, prometheus.GaugeValue, float64(count), topic)
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/engine/memory"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New()
+	inner, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	e := api.Chain(inner, m.Middleware())
+	ctx := context.Background()
+
+	_, _ = e.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	_, _ = e.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	res, _ := e.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	_, _ = e.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	_, _ = e.Ack(ctx, api.AckRequest{JobId: res.JobId, Consumer: "c2"})
+	_, _ = e.ReQueue(ctx, api.RequeueRequest{Topic: "t1", RequeueTs: time.Now().Add(time.Second).UnixMilli()})
+
+	tests := []struct {
+		labels []string
+		want   float64
+	}{
+		{labels: []string{"enqueue", "t1", "ok"}, want: 1},
+		{labels: []string{"dequeue", "t1", "ok"}, want: 1},
+		{labels: []string{"dequeue", "t1", "empty"}, want: 1},
+		{labels: []string{"ack", "", "wrong_consumer"}, want: 1},
+		{labels: []string{"requeue", "t1", "ok"}, want: 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(m.requests.WithLabelValues(tt.labels...)); got != tt.want {
+			t.Errorf("requests%v got = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+
+	if got := testutil.ToFloat64(m.leaseExpirations.WithLabelValues("t1")); got != 1 {
+		t.Errorf("lease expirations got = %v, want 1", got)
+	}
+
+	if _, ok := e.(api.Inspector); !ok {
+		t.Errorf("Middleware() engine should implement api.Inspector")
+	}
+}
@@ -0,0 +1,251 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hextechpal/prio/core"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	priov1 "github.com/hextechpal/prio/proto/prio/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	subscribeWait = 30 * time.Second       // subscribeWait: wait timeout of the dequeue calls of a subscription
+	inFlightCheck = 500 * time.Millisecond // inFlightCheck: interval of the oldest in flight job check of a paused subscription
+)
+
+type (
+	// Server : Serves the prio grpc service on top of a worker
+	Server struct {
+		priov1.UnimplementedPrioServer
+		w      *core.Worker
+		logger commons.Logger
+
+		mu        sync.Mutex
+		delivered map[int64]*subscription // delivered: in flight jobs of the subscriptions of this server, released by their Ack
+	}
+
+	// subscription : Jobs delivered on a Subscribe stream and not settled yet, in delivery order
+	subscription struct {
+		consumer string
+		inFlight []int64
+		released chan int64 // released: jobs acked through this server
+	}
+)
+
+func NewServer(w *core.Worker, logger commons.Logger) *Server {
+	return &Server{w: w, logger: logger, delivered: make(map[int64]*subscription)}
+}
+
+// Register : Registers the prio service on the grpc server
+func (s *Server) Register(gs *grpc.Server) {
+	priov1.RegisterPrioServer(gs, s)
+}
+
+// toStatus : Converts the error to a grpc status with the code of api.GRPCCode
+func toStatus(err error) error {
+	return status.Error(codes.Code(api.GRPCCode(err)), err.Error())
+}
+
+func (s *Server) GetTopics(ctx context.Context, _ *priov1.GetTopicsRequest) (*priov1.GetTopicsResponse, error) {
+	topics, err := s.w.GetTopics(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.GetTopicsResponse{Topics: topics}, nil
+}
+
+func (s *Server) RegisterTopic(ctx context.Context, req *priov1.RegisterTopicRequest) (*priov1.RegisterTopicResponse, error) {
+	_, err := s.w.RegisterTopic(ctx, api.RegisterTopicRequest{Name: req.Name, Description: req.Description, Placement: req.Placement})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.RegisterTopicResponse{}, nil
+}
+
+func (s *Server) Enqueue(ctx context.Context, req *priov1.EnqueueRequest) (*priov1.EnqueueResponse, error) {
+	res, err := s.w.Enqueue(ctx, api.EnqueueRequest{Topic: req.Topic, Priority: req.Priority, Payload: req.Payload, Headers: req.Headers})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.EnqueueResponse{JobId: res.JobId}, nil
+}
+
+func (s *Server) Dequeue(ctx context.Context, req *priov1.DequeueRequest) (*priov1.DequeueResponse, error) {
+	res, err := s.w.Dequeue(ctx, api.DequeueRequest{Topic: req.Topic, Consumer: req.Consumer, WaitTimeout: req.WaitTimeout.AsDuration()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if res.JobId == 0 {
+		return &priov1.DequeueResponse{}, nil
+	}
+	return &priov1.DequeueResponse{Job: toJob(res)}, nil
+}
+
+func (s *Server) GetJob(ctx context.Context, req *priov1.GetJobRequest) (*priov1.GetJobResponse, error) {
+	res, err := s.w.GetJob(ctx, api.GetJobRequest{JobId: req.JobId})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.GetJobResponse{
+		Job: &priov1.Job{
+			JobId:    res.JobId,
+			Topic:    res.Topic,
+			Payload:  res.Payload,
+			Priority: res.Priority,
+			Headers:  res.Headers,
+		},
+		Status:    priov1.JobStatus(res.Status),
+		ClaimedBy: res.ClaimedBy,
+	}, nil
+}
+
+func (s *Server) Ack(ctx context.Context, req *priov1.AckRequest) (*priov1.AckResponse, error) {
+	res, err := s.w.Ack(ctx, api.AckRequest{JobId: req.JobId, Consumer: req.Consumer})
+	if err == nil || errors.Is(err, api.ErrorLeaseExceeded) {
+		s.release(req.JobId)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.AckResponse{Acked: res.Acked}, nil
+}
+
+func (s *Server) ReQueue(ctx context.Context, req *priov1.ReQueueRequest) (*priov1.ReQueueResponse, error) {
+	res, err := s.w.ReQueue(ctx, api.RequeueRequest{Topic: req.Topic, RequeueTs: req.RequeueTs})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &priov1.ReQueueResponse{Count: res.Count}, nil
+}
+
+// Subscribe : Long polls the topic and sends the claimed jobs until the client goes away
+func (s *Server) Subscribe(req *priov1.SubscribeRequest, stream priov1.Prio_SubscribeServer) error {
+	if req.Topic == "" || req.Consumer == "" {
+		return status.Error(codes.InvalidArgument, "topic and consumer are required")
+	}
+
+	ctx := stream.Context()
+	sub := &subscription{consumer: req.Consumer, released: make(chan int64, req.MaxInFlight)}
+	defer s.untrack(sub)
+	for {
+		if err := s.waitInFlight(ctx, req, sub); err != nil {
+			return toStatus(err)
+		}
+
+		res, err := s.w.Dequeue(ctx, api.DequeueRequest{Topic: req.Topic, Consumer: req.Consumer, WaitTimeout: subscribeWait})
+		if err != nil {
+			return toStatus(err)
+		}
+		if res.JobId == 0 {
+			continue
+		}
+
+		if req.MaxInFlight > 0 {
+			s.track(sub, res.JobId)
+		}
+		if err = stream.Send(toJob(res)); err != nil {
+			s.logger.Debug("subscription closed topic=%s consumer=%s job=%d not delivered", req.Topic, req.Consumer, res.JobId)
+			return err
+		}
+	}
+}
+
+// waitInFlight : Blocks while max_in_flight delivered jobs are in flight. Jobs acked through this server are released
+// right away, the oldest job is checked on every tick to release the jobs acked elsewhere or re-queued
+func (s *Server) waitInFlight(ctx context.Context, req *priov1.SubscribeRequest, sub *subscription) error {
+	ticker := time.NewTicker(inFlightCheck)
+	defer ticker.Stop()
+	for req.MaxInFlight > 0 && s.inFlight(sub) >= int(req.MaxInFlight) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.released:
+		case <-ticker.C:
+			if err := s.checkOldest(ctx, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkOldest : Releases the oldest in flight job unless it is still claimed by the consumer
+func (s *Server) checkOldest(ctx context.Context, sub *subscription) error {
+	s.mu.Lock()
+	if len(sub.inFlight) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	id := sub.inFlight[0]
+	s.mu.Unlock()
+
+	job, err := s.w.GetJob(ctx, api.GetJobRequest{JobId: id})
+	if err != nil && !errors.Is(err, api.ErrorJobNotPresent) {
+		return err
+	}
+	if err != nil || job.Status != api.JobClaimed || job.ClaimedBy != sub.consumer {
+		s.release(id)
+	}
+	return nil
+}
+
+func (s *Server) track(sub *subscription, id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub.inFlight = append(sub.inFlight, id)
+	s.delivered[id] = sub
+}
+
+// release : Takes the job out of flight and wakes its subscription, it is a no-op for a job not delivered by a subscription
+func (s *Server) release(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.delivered[id]
+	if !ok {
+		return
+	}
+	delete(s.delivered, id)
+	for i, inFlight := range sub.inFlight {
+		if inFlight == id {
+			sub.inFlight = append(sub.inFlight[:i], sub.inFlight[i+1:]...)
+			break
+		}
+	}
+
+	select {
+	case sub.released <- id:
+	default:
+	}
+}
+
+func (s *Server) inFlight(sub *subscription) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(sub.inFlight)
+}
+
+// untrack : Forgets the in flight jobs of a closed subscription
+func (s *Server) untrack(sub *subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range sub.inFlight {
+		delete(s.delivered, id)
+	}
+}
+
+func toJob(res api.DequeueResponse) *priov1.Job {
+	return &priov1.Job{
+		JobId:    res.JobId,
+		Topic:    res.Topic,
+		Payload:  res.Payload,
+		Priority: res.Priority,
+		Headers:  res.Headers,
+	}
+}
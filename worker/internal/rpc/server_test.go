@@ -0,0 +1,220 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hextechpal/prio/core"
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/engine/memory"
+	priov1 "github.com/hextechpal/prio/proto/prio/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T) priov1.PrioClient {
+	t.Helper()
+	engine, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	NewServer(core.NewWorker(nil, engine), &commons.DefaultLogger{}).Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() err=%v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	client := priov1.NewPrioClient(conn)
+	if _, err = client.RegisterTopic(context.Background(), &priov1.RegisterTopicRequest{Name: "t1"}); err != nil {
+		t.Fatalf("RegisterTopic() err=%v", err)
+	}
+	return client
+}
+
+func TestServer(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	enq, err := client.Enqueue(ctx, &priov1.EnqueueRequest{Topic: "t1", Priority: 1, Payload: []byte("payload"), Headers: map[string]string{"tenant": "acme"}})
+	if err != nil {
+		t.Fatalf("Enqueue() err=%v", err)
+	}
+
+	deq, err := client.Dequeue(ctx, &priov1.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	if err != nil || deq.Job.GetJobId() != enq.JobId || deq.Job.Headers["tenant"] != "acme" {
+		t.Fatalf("Dequeue() got = %v, err=%v, want job=%d with its headers", deq, err, enq.JobId)
+	}
+
+	if empty, err := client.Dequeue(ctx, &priov1.DequeueRequest{Topic: "t1", Consumer: "c1"}); err != nil || empty.Job != nil {
+		t.Errorf("Dequeue() on an empty topic got = %v, err=%v, want no job", empty, err)
+	}
+
+	job, err := client.GetJob(ctx, &priov1.GetJobRequest{JobId: enq.JobId})
+	if err != nil || job.Status != priov1.JobStatus_JOB_STATUS_CLAIMED || job.ClaimedBy != "c1" {
+		t.Errorf("GetJob() got = %v, err=%v, want claimed by c1", job, err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *priov1.AckRequest
+		wantCode codes.Code
+	}{
+		{name: "Wrong consumer", req: &priov1.AckRequest{JobId: enq.JobId, Consumer: "c2"}, wantCode: codes.PermissionDenied},
+		{name: "Ack", req: &priov1.AckRequest{JobId: enq.JobId, Consumer: "c1"}, wantCode: codes.OK},
+		{name: "Already acked", req: &priov1.AckRequest{JobId: enq.JobId, Consumer: "c1"}, wantCode: codes.FailedPrecondition},
+		{name: "Not present", req: &priov1.AckRequest{JobId: enq.JobId + 1, Consumer: "c1"}, wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Ack(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Ack() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServer_Subscribe(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		_, _ = client.Enqueue(ctx, &priov1.EnqueueRequest{Topic: "t1", Priority: 1})
+	}
+
+	stream, err := client.Subscribe(ctx, &priov1.SubscribeRequest{Topic: "t1", Consumer: "c1", MaxInFlight: 2})
+	if err != nil {
+		t.Fatalf("Subscribe() err=%v", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() err=%v", err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatalf("Recv() err=%v", err)
+	}
+
+	// two jobs in flight, the third one stays pending until one is acked
+	time.Sleep(200 * time.Millisecond)
+	if deq, _ := client.Dequeue(ctx, &priov1.DequeueRequest{Topic: "t1", Consumer: "c2"}); deq.GetJob() == nil {
+		t.Fatalf("Dequeue() got no job, the subscription should hold back the third job")
+	}
+
+	_, _ = client.Enqueue(ctx, &priov1.EnqueueRequest{Topic: "t1", Priority: 1})
+	if _, err = client.Ack(ctx, &priov1.AckRequest{JobId: first.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Ack() err=%v", err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Errorf("Recv() after the ack err=%v", err)
+	}
+}
+
+func TestServer_Subscribe_InvalidArgument(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.Subscribe(context.Background(), &priov1.SubscribeRequest{Topic: "t1"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Subscribe() err=%v, want %v", err, codes.InvalidArgument)
+	}
+}
+
+func Test_toStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{err: api.ErrorInvalidRequest, want: codes.InvalidArgument},
+		{err: api.ErrorJobNotAcquired, want: codes.Aborted},
+		{err: api.ErrorLeaseExceeded, want: codes.FailedPrecondition},
+		{err: api.ErrorNotSupported, want: codes.Unimplemented},
+		{err: context.Canceled, want: codes.Canceled},
+		{err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{err: errors.New("connection refused"), want: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := status.Code(toStatus(tt.err)); got != tt.want {
+				t.Errorf("toStatus() code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Ack_ReleasesSubscription(t *testing.T) {
+	engine, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	w := core.NewWorker(nil, engine)
+	s := NewServer(w, &commons.DefaultLogger{})
+	ctx := context.Background()
+	_, _ = w.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+	deq, _ := w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+
+	sub := &subscription{consumer: "c1", released: make(chan int64, 1)}
+	s.track(sub, deq.JobId)
+	if _, err = s.Ack(ctx, &priov1.AckRequest{JobId: deq.JobId, Consumer: "c1"}); err != nil {
+		t.Fatalf("Ack() err=%v", err)
+	}
+
+	// the slot is released without looking the job up
+	select {
+	case id := <-sub.released:
+		if id != deq.JobId || s.inFlight(sub) != 0 || len(s.delivered) != 0 {
+			t.Errorf("released job=%d in flight=%d, want job=%d and nothing in flight", id, s.inFlight(sub), deq.JobId)
+		}
+	default:
+		t.Errorf("Ack() did not release the subscription slot")
+	}
+}
+
+func TestServer_checkOldest(t *testing.T) {
+	engine, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	w := core.NewWorker(nil, engine)
+	s := NewServer(w, &commons.DefaultLogger{})
+	ctx := context.Background()
+	_, _ = w.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	sub := &subscription{consumer: "c1", released: make(chan int64, 2)}
+	for i := 0; i < 2; i++ {
+		_, _ = w.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1})
+		deq, _ := w.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+		s.track(sub, deq.JobId)
+	}
+	oldest, newest := sub.inFlight[0], sub.inFlight[1]
+
+	// the newest job acked on another worker is only seen once it is the oldest
+	_, _ = w.Ack(ctx, api.AckRequest{JobId: newest, Consumer: "c1"})
+	if err = s.checkOldest(ctx, sub); err != nil || s.inFlight(sub) != 2 {
+		t.Fatalf("checkOldest() in flight=%d, err=%v, want the claimed oldest job kept", s.inFlight(sub), err)
+	}
+
+	_, _ = w.Ack(ctx, api.AckRequest{JobId: oldest, Consumer: "c1"})
+	if err = s.checkOldest(ctx, sub); err != nil || s.inFlight(sub) != 1 || sub.inFlight[0] != newest {
+		t.Errorf("checkOldest() in flight=%v, err=%v, want the oldest job released", sub.inFlight, err)
+	}
+}
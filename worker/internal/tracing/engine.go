@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/hextechpal/prio/core/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	topicKey    = attribute.Key("prio.topic")
+	jobIdKey    = attribute.Key("prio.job_id")
+	priorityKey = attribute.Key("prio.priority")
+	consumerKey = attribute.Key("prio.consumer")
+)
+
+type tracingEngine struct {
+	api.Engine
+	t *Tracing
+}
+
+// Middleware : Starts a span around every engine call. Enqueue stores the trace context in the job headers,
+// so that Dequeue hands it to the consumer which can link its own spans to the producer
+func (t *Tracing) Middleware() api.Middleware {
+	return func(e api.Engine) api.Engine {
+		return &tracingEngine{Engine: e, t: t}
+	}
+}
+
+func (e *tracingEngine) GetTopics(ctx context.Context) ([]string, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.get_topics")
+	defer span.End()
+	topics, err := e.Engine.GetTopics(ctx)
+	end(span, err)
+	return topics, err
+}
+
+func (e *tracingEngine) RegisterTopic(ctx context.Context, req api.RegisterTopicRequest) (api.RegisterTopicResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.register_topic", trace.WithAttributes(topicKey.String(req.Name)))
+	defer span.End()
+	res, err := e.Engine.RegisterTopic(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) Enqueue(ctx context.Context, req api.EnqueueRequest) (api.EnqueueResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.enqueue",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(topicKey.String(req.Topic), priorityKey.Int64(int64(req.Priority))),
+	)
+	defer span.End()
+
+	// the caller headers are copied, the map is owned by the caller
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	e.t.propagator.Inject(ctx, propagation.MapCarrier(headers))
+	req.Headers = headers
+
+	res, err := e.Engine.Enqueue(ctx, req)
+	if err == nil {
+		span.SetAttributes(jobIdKey.Int64(res.JobId))
+	}
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) Dequeue(ctx context.Context, req api.DequeueRequest) (api.DequeueResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.dequeue",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(topicKey.String(req.Topic), consumerKey.String(req.Consumer)),
+	)
+	defer span.End()
+	res, err := e.Engine.Dequeue(ctx, req)
+	if err == nil && res.JobId != 0 {
+		span.SetAttributes(jobIdKey.Int64(res.JobId))
+	}
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) GetJob(ctx context.Context, req api.GetJobRequest) (api.GetJobResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.get_job", trace.WithAttributes(jobIdKey.Int64(req.JobId)))
+	defer span.End()
+	res, err := e.Engine.GetJob(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) Ack(ctx context.Context, req api.AckRequest) (api.AckResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.ack", trace.WithAttributes(jobIdKey.Int64(req.JobId), consumerKey.String(req.Consumer)))
+	defer span.End()
+	res, err := e.Engine.Ack(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) ReQueue(ctx context.Context, req api.RequeueRequest) (api.RequeueResponse, error) {
+	ctx, span := e.t.tracer.Start(ctx, "prio.requeue", trace.WithAttributes(topicKey.String(req.Topic)))
+	defer span.End()
+	res, err := e.Engine.ReQueue(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) Purge(ctx context.Context, req api.PurgeRequest) (api.PurgeResponse, error) {
+	p, ok := e.Engine.(api.Purger)
+	if !ok {
+		return api.PurgeResponse{}, api.ErrorNotSupported
+	}
+	ctx, span := e.t.tracer.Start(ctx, "prio.purge", trace.WithAttributes(topicKey.String(req.Topic)))
+	defer span.End()
+	res, err := p.Purge(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) PendingCount(ctx context.Context, topic string) (int64, error) {
+	i, ok := e.Engine.(api.Inspector)
+	if !ok {
+		return 0, api.ErrorNotSupported
+	}
+	return i.PendingCount(ctx, topic)
+}
+
+func (e *tracingEngine) ExtendLease(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := e.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	ctx, span := e.t.tracer.Start(ctx, "prio.extend_lease", trace.WithAttributes(jobIdKey.Int64(req.JobId), consumerKey.String(req.Consumer)))
+	defer span.End()
+	res, err := l.ExtendLease(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+func (e *tracingEngine) Nack(ctx context.Context, req api.LeaseRequest) (api.LeaseResponse, error) {
+	l, ok := e.Engine.(api.Leaser)
+	if !ok {
+		return api.LeaseResponse{}, api.ErrorNotSupported
+	}
+	ctx, span := e.t.tracer.Start(ctx, "prio.nack", trace.WithAttributes(jobIdKey.Int64(req.JobId), consumerKey.String(req.Consumer)))
+	defer span.End()
+	res, err := l.Nack(ctx, req)
+	end(span, err)
+	return res, err
+}
+
+// WaitForJob : Not traced, the call blocks until a job is enqueued
+func (e *tracingEngine) WaitForJob(ctx context.Context, topic string) error {
+	n, ok := e.Engine.(api.Notifier)
+	if !ok {
+		return api.ErrorNotSupported
+	}
+	return n.WaitForJob(ctx, topic)
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "prio"
+	tracerName  = "github.com/hextechpal/prio/app/internal/tracing"
+)
+
+type (
+	// Config : Exporter is stdout or file, tracing is disabled if empty. File is the output of the file exporter
+	Config struct {
+		Exporter string
+		File     string
+	}
+
+	Tracing struct {
+		provider   trace.TracerProvider
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+		shutdown   func(ctx context.Context) error
+	}
+)
+
+// New : Creates the tracer provider for the configured exporter, spans are dropped if no exporter is configured
+func New(c Config) (*Tracing, error) {
+	propagator := propagation.TraceContext{}
+	otel.SetTextMapPropagator(propagator)
+
+	if c.Exporter == "" {
+		return newTracing(trace.NewNoopTracerProvider(), propagator, func(context.Context) error { return nil }), nil
+	}
+
+	out, closeOut, err := output(c)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		_ = closeOut()
+		return nil, err
+	}
+	return NewWithExporter(exporter, closeOut), nil
+}
+
+// NewWithExporter : Creates the tracer provider on top of the exporter, closer is called on shutdown once the spans are flushed
+func NewWithExporter(exporter sdktrace.SpanExporter, closer func() error) *Tracing {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return newTracing(tp, propagation.TraceContext{}, func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	})
+}
+
+func newTracing(tp trace.TracerProvider, propagator propagation.TextMapPropagator, shutdown func(ctx context.Context) error) *Tracing {
+	return &Tracing{
+		provider:   tp,
+		tracer:     tp.Tracer(tracerName),
+		propagator: propagator,
+		shutdown:   shutdown,
+	}
+}
+
+func output(c Config) (io.Writer, func() error, error) {
+	switch c.Exporter {
+	case "stdout":
+		return os.Stdout, func() error { return nil }, nil
+	case "file":
+		if c.File == "" {
+			return nil, nil, fmt.Errorf("tracing file exporter requires a file")
+		}
+		f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported tracing exporter %s", c.Exporter)
+	}
+}
+
+// Echo : Starts a span for every http request, the span context of the caller is extracted from the traceparent header
+func (t *Tracing) Echo() echo.MiddlewareFunc {
+	return otelecho.Middleware(serviceName,
+		otelecho.WithTracerProvider(t.provider),
+		otelecho.WithPropagators(t.propagator),
+	)
+}
+
+// Shutdown : Flushes the pending spans and closes the exporter
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	return t.shutdown(ctx)
+}
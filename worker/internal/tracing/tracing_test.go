@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hextechpal/prio/core/api"
+	"github.com/hextechpal/prio/engine/memory"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_Middleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tr := NewWithExporter(exporter, nil)
+	inner, err := memory.NewEngine()
+	if err != nil {
+		t.Fatalf("memory.NewEngine() err=%v", err)
+	}
+	e := api.Chain(inner, tr.Middleware())
+	ctx := context.Background()
+
+	headers := map[string]string{"content-type": "application/json"}
+	_, _ = e.RegisterTopic(ctx, api.RegisterTopicRequest{Name: "t1"})
+	_, _ = e.Enqueue(ctx, api.EnqueueRequest{Topic: "t1", Priority: 1, Headers: headers})
+	res, _ := e.Dequeue(ctx, api.DequeueRequest{Topic: "t1", Consumer: "c1"})
+	_, _ = e.Ack(ctx, api.AckRequest{JobId: res.JobId, Consumer: "c2"})
+
+	// the in memory exporter drops its spans on shutdown
+	if err = tr.provider.(*sdktrace.TracerProvider).ForceFlush(ctx); err != nil {
+		t.Fatalf("ForceFlush() err=%v", err)
+	}
+
+	if _, ok := headers["traceparent"]; ok {
+		t.Errorf("Enqueue() should not modify the caller headers")
+	}
+	if res.Headers["content-type"] != "application/json" {
+		t.Errorf("Dequeue() headers = %v, want the enqueued headers", res.Headers)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	for _, name := range []string{"prio.register_topic", "prio.enqueue", "prio.dequeue", "prio.ack"} {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("span %s not exported", name)
+		}
+	}
+
+	producer := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(res.Headers)))
+	if got, want := producer.SpanID(), byName["prio.enqueue"].SpanContext.SpanID(); got != want {
+		t.Errorf("Dequeue() trace context span = %v, want the enqueue span %v", got, want)
+	}
+	if byName["prio.ack"].Status.Code.String() != "Error" {
+		t.Errorf("ack span status = %v, want Error", byName["prio.ack"].Status.Code)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{name: "disabled", c: Config{}},
+		{name: "stdout", c: Config{Exporter: "stdout"}},
+		{name: "file", c: Config{Exporter: "file", File: t.TempDir() + "/spans.json"}},
+		{name: "file without path", c: Config{Exporter: "file"}, wantErr: true},
+		{name: "unknown", c: Config{Exporter: "jaeger"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := New(tt.c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				_ = tr.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestTracing_Middleware_NotSupported(t *testing.T) {
+	e := api.Chain(struct{ api.Engine }{}, NewWithExporter(tracetest.NewInMemoryExporter(), nil).Middleware())
+	if _, err := e.(api.Purger).Purge(context.Background(), api.PurgeRequest{Topic: "t1"}); err != api.ErrorNotSupported {
+		t.Errorf("Purge() err=%v, want %v", err, api.ErrorNotSupported)
+	}
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/core"
+)
+
+func Test_digest_auth_acl(t *testing.T) {
+	namespace := fmt.Sprintf("auth_%s", ns)
+	root := "/" + namespace
+
+	// a namespace created before auth was enabled gets restricted on start
+	if _, err := conn.Create(root, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatalf("Create(%s) err=%v", root, err)
+	}
+
+	authConn, _, err := zk.Connect([]string{zkHost}, 10*time.Second)
+	if err != nil {
+		t.Fatalf("zk.Connect() err=%v", err)
+	}
+	defer authConn.Close()
+	if err = authConn.AddAuth("digest", []byte("prio:secret")); err != nil {
+		t.Fatalf("AddAuth() err=%v", err)
+	}
+	defer deleteTree(authConn, root)
+
+	w := core.NewWorker([]string{zkHost}, engine, core.WithNamespace(namespace), core.WithDigestAuth("prio", "secret"))
+	if err = w.Start(ctx); err != nil {
+		t.Fatalf("Start() err=%v", err)
+	}
+	defer w.ShutDown()
+
+	for _, path := range []string{root, root + "/election", root + "/members", root + "/members/" + w.ID} {
+		acl, _, err := authConn.GetACL(path)
+		if err != nil || len(acl) != 1 || acl[0].Scheme != "digest" || acl[0].ID != zk.DigestACL(zk.PermAll, "prio", "secret")[0].ID {
+			t.Errorf("GetACL(%s) got = %v, err=%v, want the prio digest acl", path, acl, err)
+		}
+	}
+
+	if _, err = conn.Create(root+"/members/intruder", nil, zk.FlagEphemeral, zk.WorldACL(zk.PermAll)); err != zk.ErrNoAuth {
+		t.Errorf("Create() without auth err=%v, want %v", err, zk.ErrNoAuth)
+	}
+}
+
+func deleteTree(c *zk.Conn, path string) {
+	children, _, _ := c.Children(path)
+	for _, child := range children {
+		deleteTree(c, path+"/"+child)
+	}
+	_ = c.Delete(path, -1)
+}
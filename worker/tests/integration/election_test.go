@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/hextechpal/prio/core/commons"
+	"github.com/hextechpal/prio/core/election"
+)
+
+func Test_election_follower_leader(t *testing.T) {
+	root := electionRoot(t)
+	a, _ := elect(t, root, "a")
+	b, _ := elect(t, root, "b")
+	c, status := elect(t, root, "c")
+	defer b.Resign()
+	defer c.Resign()
+
+	if status.Leader != "a" {
+		t.Fatalf("follower c leader = %s, want a", status.Leader)
+	}
+
+	// c follows b, only the watch on the lowest candidate tells it that a is gone
+	a.Resign()
+	if status = nextStatus(t, b); status.Role != election.LEADER {
+		t.Errorf("b role = %d, want %d", status.Role, election.LEADER)
+	}
+	if status = nextStatus(t, c); status.Leader != "b" {
+		t.Errorf("follower c leader = %s, want b", status.Leader)
+	}
+}
+
+func Test_election_observer(t *testing.T) {
+	root := electionRoot(t)
+	o, err := election.NewObserver(conn, root, &commons.DefaultLogger{})
+	if err != nil {
+		t.Fatalf("NewObserver() err=%v", err)
+	}
+
+	var mu sync.Mutex
+	var changes []string
+	o.OnLeaderChange(func(leader string) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, leader)
+	})
+	go o.Observe()
+	defer o.Stop()
+
+	if leader := nextLeader(t, o); leader != "" {
+		t.Errorf("Leaders() got = %s, want no leader", leader)
+	}
+
+	a, _ := elect(t, root, "a")
+	if leader := nextLeader(t, o); leader != "a" {
+		t.Errorf("Leaders() got = %s, want a", leader)
+	}
+
+	b, _ := elect(t, root, "b")
+	defer b.Resign()
+	if leader, err := o.Leader(); err != nil || leader != "a" {
+		t.Errorf("Leader() got = %s, err=%v, want a", leader, err)
+	}
+
+	a.Resign()
+	if leader := nextLeader(t, o); leader != "b" {
+		t.Errorf("Leaders() got = %s, want b", leader)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"", "a", "b"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("OnLeaderChange() calls = %v, want %v", changes, want)
+	}
+}
+
+func electionRoot(t *testing.T) string {
+	t.Helper()
+	root := fmt.Sprintf("/%s_%s", ns, t.Name())
+	if _, err := conn.Create(root, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatalf("Create(%s) err=%v", root, err)
+	}
+	t.Cleanup(func() {
+		children, _, _ := conn.Children(root)
+		for _, child := range children {
+			_ = conn.Delete(root+"/"+child, -1)
+		}
+		_ = conn.Delete(root, -1)
+	})
+	return root
+}
+
+func elect(t *testing.T, root, candidateId string) (*election.Elector, election.Status) {
+	t.Helper()
+	e, err := election.NewElector(conn, root, &commons.DefaultLogger{})
+	if err != nil {
+		t.Fatalf("NewElector() err=%v", err)
+	}
+	go e.Elect(candidateId)
+	return e, nextStatus(t, e)
+}
+
+func nextStatus(t *testing.T, e *election.Elector) election.Status {
+	t.Helper()
+	select {
+	case status := <-e.Status():
+		if status.Err != nil {
+			t.Fatalf("Status() err=%v", status.Err)
+		}
+		return status
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Status() no update")
+	}
+	return election.Status{}
+}
+
+func nextLeader(t *testing.T, o *election.Observer) string {
+	t.Helper()
+	select {
+	case leader := <-o.Leaders():
+		return leader
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Leaders() no update")
+	}
+	return ""
+}
@@ -31,7 +31,10 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	engine = memory.NewEngine()
+	engine, err = memory.NewEngine()
+	if err != nil {
+		panic(err)
+	}
 }
 
 type resp struct {